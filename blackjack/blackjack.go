@@ -2,6 +2,7 @@
 package blackjack
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/dwlnetnl/cards/card"
@@ -37,6 +38,8 @@ const (
 	Surrendered
 	Blackjack
 	DealerBlackjack
+	EvenMoney
+	Bonus
 )
 
 //go:generate stringer -type=Outcome
@@ -44,7 +47,10 @@ const (
 // UI defines the game engine interface for user interaction.
 type UI interface {
 	Bet(fortune *player.Fortune) (amount decimal.Decimal)
-	Hand(dealer, player Hand)
+	BetRejected(err *BetError)
+	Hand(dealer DealerHand, player Hand)
+	DealerPeek(blackjack bool)
+	DealerReveal(dealer Hand)
 	DealerCard(card card.Card, hand Hand)
 	NextAction(actions []Action) Action
 	SplitHand(left, right Hand, amount decimal.Decimal)
@@ -56,6 +62,10 @@ type UI interface {
 
 	PerfectPairBet(fortune *player.Fortune) (amount decimal.Decimal)
 	PerfectPair(kind PerfectPair, amount decimal.Decimal)
+
+	InsuranceBet(fortune *player.Fortune, max decimal.Decimal) (amount decimal.Decimal)
+	Insurance(won bool, amount decimal.Decimal)
+	TakeEvenMoney() bool
 }
 
 type bet struct {
@@ -63,233 +73,209 @@ type bet struct {
 	amount    decimal.Decimal
 	doubled   bool
 	blackjack bool
+	splitAces bool
+	bonus     BonusHand
+	done      bool
+	settled   bool
 }
 
+// game is the state of a player seated at a table.
 type game struct {
-	ui       UI
-	rules    Rules
-	fortune  *player.Fortune
-	shuffler *card.Shuffler
-	dealer   Hand
-	bets     []*bet
+	*table
+	seat    int
+	ui      UI
+	fortune *player.Fortune
+	bets    []*bet
+	insured decimal.Decimal
+	answer  chan Move // unanswered question to the UI
+	sum     *Summary
 }
 
-var newShuffler = card.NewShuffler // for testing
-
-// Play starts a blackjack game.
-func Play(ui UI, r Rules, f *player.Fortune) {
-	s := newShuffler(card.NewStandardDeck(), r.NumDecks())
-	g := &game{ui: ui, rules: r, fortune: f, shuffler: s}
-
-	for {
-		amount := g.bet()
-		if amount.Equal(decimal.Zero) {
-			return
-		}
-
-		g.setup(amount)
-
-		if g.rules.PerfectPair() {
-			g.perfectPair()
-		}
+var newShuffler = card.NewSeededShuffler // for testing
 
-		b := g.bets[0]
-		if g.dealer.IsBlackjack() {
-			ui.Outcome(DealerBlackjack, b.amount, g.dealer, b.hand)
-		} else {
-			if b.hand.IsBlackjack() {
-				g.blackjack(b)
-			} else {
-				g.play()
-			}
-		}
-
-		g.cleanup()
+// Play starts a blackjack game of a single player and returns the summary
+// of the session, it returns an error like Table.Play.
+func Play(ui UI, r Rules, f *player.Fortune) (Summary, error) {
+	return PlayContext(context.Background(), ui, r, f)
+}
 
-		if !ui.NewGame(f) {
-			return
-		}
+// PlayContext is like Play but stops when ctx is done, like
+// Table.PlayContext.
+func PlayContext(ctx context.Context, ui UI, r Rules, f *player.Fortune) (Summary, error) {
+	t := NewTable(r)
+	if err := t.Join(0, ui, f); err != nil {
+		return Summary{}, err
 	}
+	err := t.PlayContext(ctx)
+	return t.Summaries()[0], err
 }
 
-func (g *game) bet() decimal.Decimal {
-	if g.fortune.Total().Equal(decimal.Zero) {
-		g.ui.NoFortune()
-		return decimal.Zero
-	}
+func (g *game) cleanup() {
+	g.bets = nil
+	g.insured = decimal.Zero
+}
 
-	amount := g.ui.Bet(g.fortune)
-	if amount.Cmp(decimal.Zero) <= 0 { // amount <= 0
-		if !g.ui.NewGame(g.fortune) {
-			return decimal.Zero
+// index returns the index of bet b in the bets of the player.
+func (g *game) index(b *bet) int {
+	for i, other := range g.bets {
+		if other == b {
+			return i
 		}
-		return g.bet()
-	}
-
-	if !g.fortune.Has(amount) {
-		return g.bet()
 	}
-
-	g.fortune.Withdrawal(amount)
-	return amount
+	return -1
 }
 
-func (g *game) setup(amount decimal.Decimal) {
-	g.dealer = Hand{g.shuffler.MustDraw()}
-	if !g.rules.NoHoleCard() {
-		g.dealer = append(g.dealer, g.shuffler.MustDraw())
+// playing returns true if the player has bets that are not settled.
+func (g *game) playing() bool {
+	for _, b := range g.bets {
+		if !b.settled {
+			return true
+		}
 	}
-
-	g.bets = append(g.bets, &bet{
-		amount: amount,
-		hand:   Hand{g.shuffler.MustDraw(), g.shuffler.MustDraw()},
-	})
+	return false
 }
 
-func (g *game) cleanup() {
-	g.shuffler.Shuffle(g.dealer...)
-	for _, b := range g.bets {
-		g.shuffler.Shuffle(b.hand...)
+// turn plays bet b until the player has to choose an action, it returns
+// false when the hand is finished. Hands that don't need a choice, like
+// a 21 or split aces, are played without one.
+func (g *game) turn(b *bet) bool {
+	if b.done || b.settled || b.blackjack {
+		return false
 	}
 
-	g.dealer = nil
-	g.bets = nil
-}
-
-func (g *game) play() {
-	if g.canEarlySurrender() {
-		b := g.bets[0]
-		g.ui.Hand(g.dealer, b.hand)
-		switch a := g.nextAction(b, []Action{Surrender, Continue}); a {
-		case Continue:
-		case Surrender:
-			g.surrender(b)
-			return
-		default:
-			panic(fmt.Sprintf("unexpected action: %v", a))
-		}
+	if len(g.bets) > 1 && g.rules.BlackjackAfterSplit() && b.hand.IsBlackjack() {
+		b.blackjack = true
+		return false
 	}
 
-	// Can't use a range expression because it evaluates the length
-	// of g.bets only once, g.bets may change during iteration.
-	for i := 0; i < len(g.bets); i++ {
-		b := g.bets[i]
-		done := false
+	for !b.done {
+		g.emit(HandEvent{Seat: g.seat, Dealer: g.dealerHand(), Player: b.hand})
 
-		if len(g.bets) > 1 && g.rules.BlackjackAfterSplit() && b.hand.IsBlackjack() {
-			b.blackjack = true
-			continue
+		v := b.hand.Value()
+		if v.Bust || g.bonus(b) {
+			break
 		}
 
-		for !done {
-			g.ui.Hand(g.dealer, b.hand)
-
-			total, soft := b.hand.Points()
-			if !soft && total >= 21 {
-				break
-			}
-
-			var a Action
-			if total == 21 {
-				a = Stand
-			} else {
-				a = g.nextAction(b, g.availableActions(b))
-			}
-
-			switch a {
-			case Hit:
-				b.hand = append(b.hand, g.shuffler.MustDraw())
-			case Stand:
-				done = true
-			case Split:
-				g.fortune.Withdrawal(b.amount)
-				lc := b.hand[0]
-				rc := b.hand[1]
-				lh := Hand{lc, g.shuffler.MustDraw()}
-				rh := Hand{rc, g.shuffler.MustDraw()}
-				b.hand = lh
-				g.bets = append(g.bets, &bet{hand: rh, amount: b.amount})
-				g.ui.SplitHand(lh, rh, b.amount)
-			case Double:
-				amount := b.amount
-				g.fortune.Withdrawal(amount)
-				b.amount = b.amount.Add(amount)
-				b.doubled = true
-				b.hand = append(b.hand, g.shuffler.MustDraw())
-				g.ui.DoubleHand(b.hand, amount)
-				done = true
-			case Surrender: // late surrender
-				g.surrender(b)
-				return
-			case Continue:
-			default:
-				panic(fmt.Sprintf("unexpected action: %v", a))
-			}
+		if v.Total() == 21 {
+			g.act(b, Stand)
+		} else if actions := g.availableActions(b); len(actions) == 1 {
+			g.act(b, actions[0])
+		} else {
+			return true
 		}
 	}
 
-	if g.rules.NoHoleCard() && len(g.dealer) == 1 {
-		c := g.shuffler.MustDraw()
-		g.dealer = append(g.dealer, c)
-		g.ui.DealerCard(c, g.dealer)
+	b.done = true
+	if b.doubled && b.bonus == NoBonus {
+		g.bonus(b)
 	}
+	return false
+}
 
-	for !g.dealerFinished() {
-		c := g.shuffler.MustDraw()
-		g.dealer = append(g.dealer, c)
-		g.ui.DealerCard(c, g.dealer)
+// act takes action a on bet b.
+func (g *game) act(b *bet, a Action) {
+	i := g.index(b)
+	g.notify(ActionEvent{Seat: g.seat, Hand: i, Action: a})
+
+	switch a {
+	case Hit:
+		b.hand = append(b.hand, g.dealTo(g.seat, i, false))
+	case Stand:
+		b.done = true
+	case Split:
+		g.fortune.Withdrawal(b.amount)
+		g.sum.wager(b.amount)
+		g.notify(BetEvent{Seat: g.seat, Kind: SplitBet, Amount: b.amount})
+		lc := b.hand[0]
+		rc := b.hand[1]
+		lh := Hand{lc, g.dealTo(g.seat, i, false)}
+		rh := Hand{rc, g.dealTo(g.seat, len(g.bets), false)}
+		aces := lc.Rank == card.Ace && rc.Rank == card.Ace
+		b.hand = lh
+		b.splitAces = b.splitAces || aces
+		g.bets = append(g.bets, &bet{hand: rh, amount: b.amount, splitAces: b.splitAces})
+		g.emit(SplitEvent{Seat: g.seat, Left: lh, Right: rh, Amount: b.amount})
+		if g.rules.BlackjackAfterSplit() && lh.IsBlackjack() {
+			b.blackjack = true
+			b.done = true
+		}
+	case Double:
+		amount := b.amount
+		g.fortune.Withdrawal(amount)
+		g.sum.wager(amount)
+		g.notify(BetEvent{Seat: g.seat, Kind: DoubleBet, Amount: amount})
+		b.amount = b.amount.Add(amount)
+		b.doubled = true
+		b.hand = append(b.hand, g.dealTo(g.seat, i, false))
+		g.emit(DoubleEvent{Seat: g.seat, Hand: b.hand, Withdrawn: amount})
+		b.done = true
+	case Surrender: // late surrender
+		g.settleBet(Surrendered, b)
+		b.done = true
+	case Continue:
+	default:
+		panic(fmt.Sprintf("unexpected action: %v", a))
 	}
+}
 
-	dealer, _ := g.dealer.Points()
+// settleHands settles the bets that are left after the dealer played.
+func (g *game) settleHands() {
+	dealer := g.dealer.Value().Total()
 	for _, b := range g.bets {
+		if b.settled {
+			continue
+		}
+
 		if b.blackjack {
-			g.blackjack(b)
+			g.settleBet(Blackjack, b)
+			continue
+		}
+
+		if b.bonus != NoBonus {
+			g.settleBet(Bonus, b)
 			continue
 		}
 
-		player, _ := b.hand.Points()
-		if player > 21 {
-			g.bust(b)
+		v := b.hand.Value()
+		player := v.Total()
+		if v.Bust {
+			g.settleBet(Bust, b)
 
 		} else if player > dealer && dealer <= 21 || dealer > 21 {
-			g.win(b)
+			g.settleBet(Won, b)
 
 		} else if player == dealer {
 			if g.rules.DealerWinsTie() {
-				g.loss(b)
+				g.settleBet(Lost, b)
 			} else {
-				g.push(b)
+				g.settleBet(Pushed, b)
 			}
 
 		} else {
-			g.loss(b)
+			g.settleBet(Lost, b)
 		}
 	}
 }
 
 func (g *game) availableActions(b *bet) []Action {
-	if g.canLateSurrender(b) {
-		return []Action{Surrender, Continue}
+	actions := []Action{Hit, Stand}
+	if b.splitAces && !g.rules.HitSplitAces() {
+		actions = []Action{Stand} // one card on split aces
 	}
 
-	actions := []Action{Hit, Stand}
-	if g.canSplit(b) {
+	if g.canSplit(b) && g.checkBet(SplitBet, b.amount) == nil {
 		actions = append(actions, Split)
 	}
 
-	if !b.doubled && g.canDouble(b) {
+	if !b.doubled && g.canDouble(b) && g.checkBet(DoubleBet, b.amount) == nil {
 		actions = append(actions, Double)
 	}
 
-	return actions
-}
-
-func (g *game) nextAction(b *bet, actions []Action) Action {
-	action := g.ui.NextAction(actions)
-	if !validAction(action, actions...) {
-		panic(fmt.Sprintf("action %v is invalid, allowed: %v", action, actions))
+	if g.canLateSurrender(b) {
+		actions = append(actions, Surrender)
 	}
-	return action
+
+	return actions
 }
 
 func validAction(action Action, actions ...Action) bool {
@@ -301,71 +287,87 @@ func validAction(action Action, actions ...Action) bool {
 	return false
 }
 
-func (g *game) dealerFinished() bool {
-	total, soft := g.dealer.Points()
-	if total == 17 && soft {
-		return !g.rules.DealerHitSoft17()
+// playerBlackjack settles a blackjack of the player. Without a hole card
+// it is settled by the dealer after drawing its second card, if the
+// dealer can make a blackjack.
+func (g *game) playerBlackjack(b *bet) {
+	b.blackjack = true
+	if g.rules.NoHoleCard() && len(g.dealer) == 1 && g.upCardCanBlackjack() {
+		return
 	}
-	return total >= 17
-}
-
-func (g *game) blackjack(b *bet) {
-	amount := b.amount.Mul(g.rules.BlackjackRatio()).Add(b.amount)
-	g.fortune.Deposit(amount)
-	g.ui.Outcome(Blackjack, amount, g.dealer, b.hand)
-}
 
-func (g *game) win(b *bet) {
-	amount := b.amount.Mul(decimal.New(2, 0))
-	g.fortune.Deposit(amount)
-	g.ui.Outcome(Won, amount, g.dealer, b.hand)
-}
-
-func (g *game) push(b *bet) {
-	g.fortune.Deposit(b.amount)
-	g.ui.Outcome(Pushed, b.amount, g.dealer, b.hand)
-}
-
-func (g *game) loss(b *bet) {
-	amount := decimal.Zero.Sub(b.amount)
-	g.ui.Outcome(Lost, amount, g.dealer, b.hand)
+	if g.dealer.IsBlackjack() {
+		g.settleBet(Pushed, b)
+	} else {
+		g.settleBet(Blackjack, b)
+	}
 }
 
-func (g *game) bust(b *bet) {
-	amount := decimal.Zero.Sub(b.amount)
-	g.ui.Outcome(Bust, amount, g.dealer, b.hand)
-}
+// dealerBlackjack settles all bets against a dealer blackjack that is
+// discovered after the player has played, which happens when the dealer
+// has no hole card or did not peek. All money including doubles and splits
+// is lost, unless only the original bet is lost when the rules say so.
+// Hands that busted are lost in full, every bet has a single outcome.
+func (g *game) dealerBlackjack() {
+	lost := g.bets[0].amount
+	if g.bets[0].doubled {
+		lost = lost.Div(decimal.New(2, 0))
+	}
 
-func (g *game) surrender(b *bet) {
-	amount := b.amount.Div(decimal.New(2, 0))
-	g.fortune.Deposit(amount)
-	g.ui.Outcome(Surrendered, amount, g.dealer, b.hand)
+	for i, b := range g.bets {
+		switch {
+		case b.settled:
+			continue
+		case b.blackjack:
+			g.settleBet(Pushed, b)
+		case b.hand.Value().Bust:
+			g.settleBet(Bust, b)
+		case !g.rules.OriginalBetsOnly():
+			g.settleBet(DealerBlackjack, b)
+		case i > 0:
+			g.settleBet(Pushed, b)
+		default:
+			// The double is refunded, the original bet is lost.
+			if refund := b.amount.Sub(lost); refund.Sign() > 0 {
+				g.fortune.Deposit(refund)
+			}
+			g.sum.hand(DealerBlackjack, g.settle(DealerBlackjack, lost, b))
+			b.settled = true
+		}
+	}
 }
 
 func (g *game) canSplit(b *bet) bool {
-	if len(b.hand) != 2 || b.hand[0].Rank != b.hand[1].Rank {
+	if len(b.hand) != 2 {
 		return false
 	}
 
-	if !g.fortune.Has(b.amount) {
+	l, r := b.hand[0], b.hand[1]
+	if l.Rank != r.Rank {
+		ten := card.BlackjackPoints.Points(l) == 10 && card.BlackjackPoints.Points(r) == 10
+		if !ten || !g.rules.SplitUnequalTens() {
+			return false
+		}
+	}
+
+	if max := g.rules.SplitHands(); max > 0 && len(g.bets) >= max {
 		return false
 	}
 
-	hands := make([]Hand, len(g.bets))
-	for i, b := range g.bets {
-		hands[i] = b.hand
+	if b.splitAces {
+		return l.Rank == card.Ace && g.rules.ResplitAces()
 	}
 
-	return g.rules.CanSplit(hands)
+	return true
 }
 
 func (g *game) canDouble(b *bet) bool {
-	if len(b.hand) > 2 || !g.fortune.Has(b.amount) {
+	if len(b.hand) > 2 {
 		return false
 	}
 
 	if dr := g.rules.Double(); dr != DoubleAny {
-		pts, _ := b.hand.Points()
+		pts := b.hand.Value().Total()
 
 		if dr == DoubleOnly9_10_11 && (pts < 9 || pts > 11) {
 			return false
@@ -376,6 +378,10 @@ func (g *game) canDouble(b *bet) bool {
 		}
 	}
 
+	if b.splitAces && !g.rules.DoubleSplitAces() {
+		return false
+	}
+
 	if len(g.bets) > 1 {
 		return g.rules.DoubleAfterSplit()
 	}
@@ -387,10 +393,32 @@ func (g *game) canEarlySurrender() bool {
 	if len(g.bets) != 1 {
 		panic("only first bet can be surrendered early")
 	}
-	return g.rules.Surrender() == EarlySurrender && len(g.dealer) == 1
+	return g.rules.Surrender() == EarlySurrender && g.canSurrenderUpCard() &&
+		!g.bets[0].hand.IsBlackjack()
 }
 
+// canLateSurrender returns true if bet b can be surrendered after the
+// dealer checked for blackjack. When the dealer could not check, it is
+// only possible if the up-card can't make a blackjack.
 func (g *game) canLateSurrender(b *bet) bool {
-	return g.rules.Surrender() == LateSurrender && len(g.dealer) == 1 &&
-		len(g.bets) == 1 && len(g.bets[0].hand) == 2 && !b.doubled
+	if g.rules.Surrender() != LateSurrender || !g.canSurrenderUpCard() {
+		return false
+	}
+
+	if !g.peeked && g.upCardCanBlackjack() {
+		return false
+	}
+
+	return len(g.bets) == 1 && len(b.hand) == 2 && !b.doubled
+}
+
+func (g *game) canSurrenderUpCard() bool {
+	switch g.rules.SurrenderUpCard() {
+	case SurrenderNotAce:
+		return g.dealer[0].Rank != card.Ace
+	case SurrenderNotAceTen:
+		return !g.upCardCanBlackjack()
+	default:
+		return true
+	}
 }
@@ -13,378 +13,418 @@ func TestUnbettedGame(t *testing.T) {
 }
 
 func TestBlackjackGame(t *testing.T) {
-	testPlay(t, 1, HollandCasino, 10, 0, []event{
+	testPlay(t, 0, HollandCasino, 10, 0, []event{
+		dealerCard{
+			card: card.Heart(card.King),
+			hand: Hand{card.Spade(card.King), card.Heart(card.King)},
+		},
 		outcome{
 			outcome: Blackjack,
 			amount:  decimal.New(25, 0),
-			dealer:  Hand{card.Heart(card.Jack)},
-			player:  Hand{card.Heart(card.Ace), card.Heart(card.Queen)},
+			dealer:  Hand{card.Spade(card.King), card.Heart(card.King)},
+			player:  Hand{card.Club(card.Ace), card.Heart(card.Queen)},
 		},
 	})
 }
 
 func TestLostGame(t *testing.T) {
-	testPlay(t, 2, HollandCasino, 10, 0, []event{
+	testPlay(t, 1166, HollandCasino, 10, 0, []event{
 		hand{
-			dealer: Hand{card.Diamond(card.Six)},
-			player: Hand{card.Heart(card.Two), card.Spade(card.Two)},
+			dealer: Hand{card.Heart(card.Ace)},
+			player: Hand{card.Heart(card.Four), card.Club(card.Four)},
 		},
 		nextAction{[]Action{Hit, Stand, Split}, Hit},
 		hand{
-			dealer: Hand{card.Diamond(card.Six)},
+			dealer: Hand{card.Heart(card.Ace)},
 			player: Hand{
-				card.Heart(card.Two),
-				card.Spade(card.Two),
-				card.Spade(card.Queen),
+				card.Heart(card.Four),
+				card.Club(card.Four),
+				card.Heart(card.Nine),
 			},
 		},
 		nextAction{[]Action{Hit, Stand}, Stand},
 		dealerCard{
-			card: card.Diamond(card.Eight),
-			hand: Hand{card.Diamond(card.Six), card.Diamond(card.Eight)},
+			card: card.Diamond(card.Five),
+			hand: Hand{card.Heart(card.Ace), card.Diamond(card.Five)},
 		},
 		dealerCard{
-			card: card.Club(card.Five),
+			card: card.Heart(card.Four),
 			hand: Hand{
-				card.Diamond(card.Six),
-				card.Diamond(card.Eight),
-				card.Club(card.Five),
+				card.Heart(card.Ace),
+				card.Diamond(card.Five),
+				card.Heart(card.Four),
 			},
 		},
 		outcome{
 			outcome: Lost,
 			amount:  decimal.New(-10, 0),
 			dealer: Hand{
-				card.Diamond(card.Six),
-				card.Diamond(card.Eight),
-				card.Club(card.Five),
+				card.Heart(card.Ace),
+				card.Diamond(card.Five),
+				card.Heart(card.Four),
 			},
 			player: Hand{
-				card.Heart(card.Two),
-				card.Spade(card.Two),
-				card.Spade(card.Queen),
+				card.Heart(card.Four),
+				card.Club(card.Four),
+				card.Heart(card.Nine),
 			},
 		},
 	})
 }
 
 func TestBustGame(t *testing.T) {
-	testPlay(t, 2, HollandCasino, 10, 0, []event{
+	testPlay(t, 1166, HollandCasino, 10, 0, []event{
 		hand{
-			dealer: Hand{card.Diamond(card.Six)},
-			player: Hand{card.Heart(card.Two), card.Spade(card.Two)},
+			dealer: Hand{card.Heart(card.Ace)},
+			player: Hand{card.Heart(card.Four), card.Club(card.Four)},
 		},
 		nextAction{[]Action{Hit, Stand, Split}, Hit},
 		hand{
-			dealer: Hand{card.Diamond(card.Six)},
+			dealer: Hand{card.Heart(card.Ace)},
 			player: Hand{
-				card.Heart(card.Two),
-				card.Spade(card.Two),
-				card.Spade(card.Queen),
+				card.Heart(card.Four),
+				card.Club(card.Four),
+				card.Heart(card.Nine),
 			},
 		},
 		nextAction{[]Action{Hit, Stand}, Hit},
 		hand{
-			dealer: Hand{card.Diamond(card.Six)},
+			dealer: Hand{card.Heart(card.Ace)},
 			player: Hand{
-				card.Heart(card.Two),
-				card.Spade(card.Two),
-				card.Spade(card.Queen),
-				card.Diamond(card.Eight),
+				card.Heart(card.Four),
+				card.Club(card.Four),
+				card.Heart(card.Nine),
+				card.Diamond(card.Five),
 			},
 		},
 		dealerCard{
-			card: card.Club(card.Five),
-			hand: Hand{card.Diamond(card.Six), card.Club(card.Five)},
+			card: card.Heart(card.Four),
+			hand: Hand{card.Heart(card.Ace), card.Heart(card.Four)},
 		},
 		dealerCard{
-			card: card.Heart(card.Two),
+			card: card.Heart(card.Five),
 			hand: Hand{
-				card.Diamond(card.Six),
-				card.Club(card.Five),
-				card.Heart(card.Two),
-			},
-		},
-		dealerCard{
-			card: card.Spade(card.Seven),
-			hand: Hand{
-				card.Diamond(card.Six),
-				card.Club(card.Five),
-				card.Heart(card.Two),
-				card.Spade(card.Seven),
+				card.Heart(card.Ace),
+				card.Heart(card.Four),
+				card.Heart(card.Five),
 			},
 		},
 		outcome{
 			outcome: Bust,
 			amount:  decimal.New(-10, 0),
 			dealer: Hand{
-				card.Diamond(card.Six),
-				card.Club(card.Five),
-				card.Heart(card.Two),
-				card.Spade(card.Seven),
+				card.Heart(card.Ace),
+				card.Heart(card.Four),
+				card.Heart(card.Five),
 			},
 			player: Hand{
-				card.Heart(card.Two),
-				card.Spade(card.Two),
-				card.Spade(card.Queen),
-				card.Diamond(card.Eight),
+				card.Heart(card.Four),
+				card.Club(card.Four),
+				card.Heart(card.Nine),
+				card.Diamond(card.Five),
 			},
 		},
 	})
 }
 
 func TestDoubleOnFirstHandOnly(t *testing.T) {
-	testPlay(t, 33, HollandCasino, 10, 0, []event{
+	testPlay(t, 254, HollandCasino, 10, 0, []event{
 		hand{
-			dealer: Hand{card.Spade(card.Ten)},
-			player: Hand{card.Club(card.Six), card.Club(card.Two)},
+			dealer: Hand{card.Heart(card.Queen)},
+			player: Hand{card.Spade(card.Seven), card.Heart(card.Two)},
 		},
-		nextAction{[]Action{Hit, Stand}, Hit},
+		nextAction{[]Action{Hit, Stand, Double}, Hit},
 		hand{
-			dealer: Hand{card.Spade(card.Ten)},
+			dealer: Hand{card.Heart(card.Queen)},
 			player: Hand{
-				card.Club(card.Six),
-				card.Club(card.Two),
+				card.Spade(card.Seven),
 				card.Heart(card.Two),
+				card.Heart(card.Jack),
 			},
 		},
 		nextAction{[]Action{Hit, Stand}, Stand},
 		dealerCard{
-			card: card.Spade(card.Nine),
-			hand: Hand{card.Spade(card.Ten), card.Spade(card.Nine)},
+			card: card.Club(card.King),
+			hand: Hand{card.Heart(card.Queen), card.Club(card.King)},
 		},
 		outcome{
 			outcome: Lost,
 			amount:  decimal.New(-10, 0),
-			dealer:  Hand{card.Spade(card.Ten), card.Spade(card.Nine)},
+			dealer:  Hand{card.Heart(card.Queen), card.Club(card.King)},
 			player: Hand{
-				card.Club(card.Six),
-				card.Club(card.Two),
+				card.Spade(card.Seven),
 				card.Heart(card.Two),
+				card.Heart(card.Jack),
 			},
 		},
 	})
 }
 
 func TestPushedGame(t *testing.T) {
-	testPlay(t, 10, HollandCasino, 10, 0, []event{
+	testPlay(t, 47, HollandCasino, 10, 0, []event{
 		hand{
-			dealer: Hand{card.Heart(card.King)},
-			player: Hand{card.Heart(card.Three), card.Club(card.Queen)},
+			dealer: Hand{card.Club(card.Seven)},
+			player: Hand{card.Heart(card.Five), card.Diamond(card.Six)},
 		},
-		nextAction{[]Action{Hit, Stand}, Hit},
+		nextAction{[]Action{Hit, Stand, Double}, Hit},
 		hand{
-			dealer: Hand{card.Heart(card.King)},
+			dealer: Hand{card.Club(card.Seven)},
 			player: Hand{
-				card.Heart(card.Three),
-				card.Club(card.Queen),
-				card.Heart(card.Eight),
+				card.Heart(card.Five),
+				card.Diamond(card.Six),
+				card.Club(card.Eight),
 			},
 		},
+		nextAction{[]Action{Hit, Stand}, Stand},
 		dealerCard{
-			card: card.Spade(card.Ace),
-			hand: Hand{card.Heart(card.King), card.Spade(card.Ace)},
+			card: card.Spade(card.Eight),
+			hand: Hand{card.Club(card.Seven), card.Spade(card.Eight)},
+		},
+		dealerCard{
+			card: card.Spade(card.Four),
+			hand: Hand{
+				card.Club(card.Seven),
+				card.Spade(card.Eight),
+				card.Spade(card.Four),
+			},
 		},
 		outcome{
 			outcome: Pushed,
 			amount:  decimal.New(10, 0),
-			dealer:  Hand{card.Heart(card.King), card.Spade(card.Ace)},
+			dealer: Hand{
+				card.Club(card.Seven),
+				card.Spade(card.Eight),
+				card.Spade(card.Four),
+			},
 			player: Hand{
-				card.Heart(card.Three),
-				card.Club(card.Queen),
-				card.Heart(card.Eight),
+				card.Heart(card.Five),
+				card.Diamond(card.Six),
+				card.Club(card.Eight),
 			},
 		},
 	})
 }
 
 func TestSplittedGame(t *testing.T) {
-	testPlay(t, 14, HollandCasino, 10, 0, []event{
+	testPlay(t, 10402, HollandCasino, 10, 0, []event{
 		hand{
-			dealer: Hand{card.Spade(card.Six)},
-			player: Hand{card.Heart(card.Eight), card.Spade(card.Eight)},
+			dealer: Hand{card.Heart(card.Five)},
+			player: Hand{card.Spade(card.King), card.Club(card.King)},
 		},
 		nextAction{[]Action{Hit, Stand, Split}, Split},
 		splitHand{
-			left:  Hand{card.Heart(card.Eight), card.Diamond(card.Ace)},
-			right: Hand{card.Spade(card.Eight), card.Club(card.Eight)},
+			left:  Hand{card.Spade(card.King), card.Diamond(card.Ten)},
+			right: Hand{card.Club(card.King), card.Spade(card.King)},
 		},
 		hand{
-			dealer: Hand{card.Spade(card.Six)},
-			player: Hand{card.Heart(card.Eight), card.Diamond(card.Ace)},
+			dealer: Hand{card.Heart(card.Five)},
+			player: Hand{card.Spade(card.King), card.Diamond(card.Ten)},
 		},
 		nextAction{[]Action{Hit, Stand}, Stand},
 		hand{
-			dealer: Hand{card.Spade(card.Six)},
-			player: Hand{card.Spade(card.Eight), card.Club(card.Eight)},
+			dealer: Hand{card.Heart(card.Five)},
+			player: Hand{card.Club(card.King), card.Spade(card.King)},
 		},
 		nextAction{[]Action{Hit, Stand, Split}, Split},
 		splitHand{
-			left:  Hand{card.Spade(card.Eight), card.Club(card.King)},
-			right: Hand{card.Club(card.Eight), card.Diamond(card.Eight)},
+			left:  Hand{card.Club(card.King), card.Diamond(card.Four)},
+			right: Hand{card.Spade(card.King), card.Diamond(card.King)},
 		},
 		hand{
-			dealer: Hand{card.Spade(card.Six)},
-			player: Hand{card.Spade(card.Eight), card.Club(card.King)},
+			dealer: Hand{card.Heart(card.Five)},
+			player: Hand{card.Club(card.King), card.Diamond(card.Four)},
 		},
 		nextAction{[]Action{Hit, Stand}, Stand},
 		hand{
-			dealer: Hand{card.Spade(card.Six)},
-			player: Hand{card.Club(card.Eight), card.Diamond(card.Eight)},
+			dealer: Hand{card.Heart(card.Five)},
+			player: Hand{card.Spade(card.King), card.Diamond(card.King)},
 		},
 		nextAction{[]Action{Hit, Stand, Split}, Split},
 		splitHand{
-			left:  Hand{card.Club(card.Eight), card.Club(card.Seven)},
-			right: Hand{card.Diamond(card.Eight), card.Heart(card.Eight)},
+			left:  Hand{card.Spade(card.King), card.Diamond(card.Ten)},
+			right: Hand{card.Diamond(card.King), card.Diamond(card.Ten)},
 		},
 		hand{
-			dealer: Hand{card.Spade(card.Six)},
-			player: Hand{card.Club(card.Eight), card.Club(card.Seven)},
+			dealer: Hand{card.Heart(card.Five)},
+			player: Hand{card.Spade(card.King), card.Diamond(card.Ten)},
 		},
 		nextAction{[]Action{Hit, Stand}, Stand},
 		hand{
-			dealer: Hand{card.Spade(card.Six)},
-			player: Hand{card.Diamond(card.Eight), card.Heart(card.Eight)},
+			dealer: Hand{card.Heart(card.Five)},
+			player: Hand{card.Diamond(card.King), card.Diamond(card.Ten)},
 		},
-		nextAction{[]Action{Hit, Stand, Split}, Stand},
+		nextAction{[]Action{Hit, Stand}, Stand},
 		dealerCard{
-			card: card.Club(card.Ten),
-			hand: Hand{card.Spade(card.Six), card.Club(card.Ten)},
+			card: card.Diamond(card.Jack),
+			hand: Hand{card.Heart(card.Five), card.Diamond(card.Jack)},
 		},
 		dealerCard{
-			card: card.Heart(card.Three),
+			card: card.Club(card.Four),
 			hand: Hand{
-				card.Spade(card.Six),
-				card.Club(card.Ten),
-				card.Heart(card.Three),
+				card.Heart(card.Five),
+				card.Diamond(card.Jack),
+				card.Club(card.Four),
 			},
 		},
 		outcome{
-			outcome: Pushed,
-			amount:  decimal.New(10, 0),
+			outcome: Won,
+			amount:  decimal.New(20, 0),
 			dealer: Hand{
-				card.Spade(card.Six),
-				card.Club(card.Ten),
-				card.Heart(card.Three),
+				card.Heart(card.Five),
+				card.Diamond(card.Jack),
+				card.Club(card.Four),
 			},
-			player: Hand{card.Heart(card.Eight), card.Diamond(card.Ace)},
+			player: Hand{card.Spade(card.King), card.Diamond(card.Ten)},
 		},
 		outcome{
 			outcome: Lost,
 			amount:  decimal.New(-10, 0),
 			dealer: Hand{
-				card.Spade(card.Six),
-				card.Club(card.Ten),
-				card.Heart(card.Three),
+				card.Heart(card.Five),
+				card.Diamond(card.Jack),
+				card.Club(card.Four),
 			},
-			player: Hand{card.Spade(card.Eight), card.Club(card.King)},
+			player: Hand{card.Club(card.King), card.Diamond(card.Four)},
 		},
 		outcome{
-			outcome: Lost,
-			amount:  decimal.New(-10, 0),
+			outcome: Won,
+			amount:  decimal.New(20, 0),
 			dealer: Hand{
-				card.Spade(card.Six),
-				card.Club(card.Ten),
-				card.Heart(card.Three),
+				card.Heart(card.Five),
+				card.Diamond(card.Jack),
+				card.Club(card.Four),
 			},
-			player: Hand{card.Club(card.Eight), card.Club(card.Seven)},
+			player: Hand{card.Spade(card.King), card.Diamond(card.Ten)},
 		},
 		outcome{
-			outcome: Lost,
-			amount:  decimal.New(-10, 0),
+			outcome: Won,
+			amount:  decimal.New(20, 0),
 			dealer: Hand{
-				card.Spade(card.Six),
-				card.Club(card.Ten),
-				card.Heart(card.Three),
+				card.Heart(card.Five),
+				card.Diamond(card.Jack),
+				card.Club(card.Four),
 			},
-			player: Hand{card.Diamond(card.Eight), card.Heart(card.Eight)},
+			player: Hand{card.Diamond(card.King), card.Diamond(card.Ten)},
 		},
 	})
 }
 
 func TestDoubledGame(t *testing.T) {
-	testPlay(t, 27, HollandCasino, 10, 0, []event{
+	testPlay(t, 144, HollandCasino, 10, 0, []event{
 		hand{
-			dealer: Hand{card.Club(card.Ten)},
-			player: Hand{card.Club(card.Two), card.Diamond(card.Seven)},
+			dealer: Hand{card.Heart(card.Eight)},
+			player: Hand{card.Heart(card.Three), card.Spade(card.Six)},
 		},
 		nextAction{[]Action{Hit, Stand, Double}, Double},
 		doubleHand{
 			hand: Hand{
-				card.Club(card.Two),
-				card.Diamond(card.Seven),
-				card.Club(card.Queen),
+				card.Heart(card.Three),
+				card.Spade(card.Six),
+				card.Diamond(card.Eight),
 			},
 			withdrawn: decimal.New(10, 0),
 		},
 		dealerCard{
-			card: card.Club(card.Five),
-			hand: Hand{card.Club(card.Ten), card.Club(card.Five)},
+			card: card.Club(card.Seven),
+			hand: Hand{card.Heart(card.Eight), card.Club(card.Seven)},
 		},
 		dealerCard{
-			card: card.Spade(card.Nine),
+			card: card.Spade(card.Ten),
 			hand: Hand{
-				card.Club(card.Ten),
-				card.Club(card.Five),
-				card.Spade(card.Nine),
+				card.Heart(card.Eight),
+				card.Club(card.Seven),
+				card.Spade(card.Ten),
 			},
 		},
 		outcome{
 			outcome: Won,
 			amount:  decimal.New(40, 0),
 			dealer: Hand{
-				card.Club(card.Ten),
-				card.Club(card.Five),
-				card.Spade(card.Nine),
+				card.Heart(card.Eight),
+				card.Club(card.Seven),
+				card.Spade(card.Ten),
 			},
 			player: Hand{
-				card.Club(card.Two),
-				card.Diamond(card.Seven),
-				card.Club(card.Queen),
+				card.Heart(card.Three),
+				card.Spade(card.Six),
+				card.Diamond(card.Eight),
 			},
 		},
 	})
 }
 
 func TestDealerLostGame(t *testing.T) {
-	testPlay(t, 16, HollandCasino, 10, 0, []event{
+	testPlay(t, 2, HollandCasino, 10, 0, []event{
 		hand{
-			dealer: Hand{card.Spade(card.Two)},
-			player: Hand{card.Diamond(card.Two), card.Heart(card.King)},
+			dealer: Hand{card.Heart(card.Two)},
+			player: Hand{card.Diamond(card.Six), card.Spade(card.Two)},
 		},
 		nextAction{[]Action{Hit, Stand}, Stand},
 		dealerCard{
-			card: card.Diamond(card.Eight),
-			hand: Hand{card.Spade(card.Two), card.Diamond(card.Eight)},
+			card: card.Spade(card.Queen),
+			hand: Hand{card.Heart(card.Two), card.Spade(card.Queen)},
 		},
 		dealerCard{
-			card: card.Spade(card.Queen),
+			card: card.Diamond(card.Eight),
 			hand: Hand{
-				card.Spade(card.Two),
-				card.Diamond(card.Eight),
+				card.Heart(card.Two),
 				card.Spade(card.Queen),
+				card.Diamond(card.Eight),
 			},
 		},
 		outcome{
 			outcome: Lost,
 			amount:  decimal.New(-10, 0),
 			dealer: Hand{
-				card.Spade(card.Two),
-				card.Diamond(card.Eight),
+				card.Heart(card.Two),
 				card.Spade(card.Queen),
+				card.Diamond(card.Eight),
 			},
-			player: Hand{card.Diamond(card.Two), card.Heart(card.King)},
+			player: Hand{card.Diamond(card.Six), card.Spade(card.Two)},
 		},
 	})
 }
 
 func TestDealerBlackjackGame(t *testing.T) {
-	testPlay(t, 15, TapTapBoom, 10, 0, []event{
+	testPlay(t, 1, TapTapBoom, 10, 0, []event{
+		insuranceBet{decimal.New(5, 0)},
+		dealerPeek{true},
 		outcome{
 			outcome: DealerBlackjack,
 			amount:  decimal.New(10, 0),
-			dealer:  Hand{card.Club(card.Ace), card.Club(card.Jack)},
-			player:  Hand{card.Heart(card.Eight), card.Spade(card.Seven)},
+			dealer:  Hand{card.Heart(card.Ace), card.Heart(card.Ten)},
+			player:  Hand{card.Heart(card.Jack), card.Heart(card.Queen)},
+		},
+	})
+}
+
+func TestDealerHoleCardGame(t *testing.T) {
+	testPlay(t, 19, TapTapBoom, 10, 0, []event{
+		hand{
+			dealer: Hand{card.Diamond(card.Five)},
+			player: Hand{card.Club(card.Four), card.Spade(card.Two)},
+		},
+		nextAction{[]Action{Hit, Stand, Double}, Stand},
+		dealerReveal{
+			hand: Hand{card.Diamond(card.Five), card.Spade(card.Ten)},
+		},
+		dealerCard{
+			card: card.Diamond(card.Nine),
+			hand: Hand{
+				card.Diamond(card.Five),
+				card.Spade(card.Ten),
+				card.Diamond(card.Nine),
+			},
+		},
+		outcome{
+			outcome: Won,
+			amount:  decimal.New(20, 0),
+			dealer: Hand{
+				card.Diamond(card.Five),
+				card.Spade(card.Ten),
+				card.Diamond(card.Nine),
+			},
+			player: Hand{card.Club(card.Four), card.Spade(card.Two)},
 		},
 	})
 }
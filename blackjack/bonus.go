@@ -0,0 +1,133 @@
+package blackjack
+
+import (
+	"github.com/dwlnetnl/cards/card"
+
+	"github.com/shopspring/decimal"
+)
+
+// BonusHand represents a special hand that wins automatically.
+type BonusHand int
+
+// Bonus hands.
+const (
+	NoBonus    BonusHand = iota
+	Charlie              // a number of cards without busting
+	Suited678            // 6-7-8 of one suit
+	Triple7              // 7-7-7
+	FiveCard21           // 21 with 5 or more cards
+)
+
+//go:generate stringer -type=BonusHand
+
+// BonusUI is a UI that is told the bonus hand a bet won with.
+// BonusOutcome is called instead of Outcome for the outcome Bonus.
+type BonusUI interface {
+	UI
+	BonusOutcome(kind BonusHand, amount decimal.Decimal, dealer, player Hand)
+}
+
+// Bonuses are the bonus hands of a game and their payout ratios. A bonus
+// with a zero ratio is not paid, except a Charlie that pays 1:1 by default.
+type Bonuses struct {
+	Charlie      int             `json:"charlie" toml:"charlie" yaml:"charlie"` // cards, zero: no Charlie
+	CharlieRatio decimal.Decimal `json:"charlieRatio" toml:"charlieRatio" yaml:"charlieRatio"`
+	Suited678    decimal.Decimal `json:"suited678" toml:"suited678" yaml:"suited678"`
+	Triple7      decimal.Decimal `json:"triple7" toml:"triple7" yaml:"triple7"`
+	FiveCard21   decimal.Decimal `json:"fiveCard21" toml:"fiveCard21" yaml:"fiveCard21"`
+}
+
+// Match returns the bonus hand h is and its payout ratio, the bonus with
+// the highest ratio if h is more than one.
+func (b Bonuses) Match(h Hand) (BonusHand, decimal.Decimal) {
+	v := h.Value()
+	if v.Bust {
+		return NoBonus, decimal.Zero
+	}
+
+	kind, ratio := NoBonus, decimal.Zero
+	match := func(k BonusHand) {
+		if r := b.ratio(k); r.Cmp(ratio) > 0 {
+			kind, ratio = k, r
+		}
+	}
+
+	if b.Charlie > 0 && len(h) >= b.Charlie {
+		match(Charlie)
+	}
+	if len(h) == 3 && h.suited() && h.hasRanks(card.Six, card.Seven, card.Eight) {
+		match(Suited678)
+	}
+	if len(h) == 3 && h.hasRanks(card.Seven, card.Seven, card.Seven) {
+		match(Triple7)
+	}
+	if len(h) >= 5 && v.Total() == 21 {
+		match(FiveCard21)
+	}
+	return kind, ratio
+}
+
+// ratio returns the payout ratio of bonus hand kind.
+func (b Bonuses) ratio(kind BonusHand) decimal.Decimal {
+	switch kind {
+	case Charlie:
+		return orDefault(b.CharlieRatio, decimal.New(1, 0))
+	case Suited678:
+		return b.Suited678
+	case Triple7:
+		return b.Triple7
+	case FiveCard21:
+		return b.FiveCard21
+	}
+	return decimal.Zero
+}
+
+// hasRanks returns true if hand h consists of cards with ranks in any
+// order.
+func (h Hand) hasRanks(ranks ...card.Rank) bool {
+	if len(h) != len(ranks) {
+		return false
+	}
+
+	var n [card.JokerWhite + 1]int
+	for _, r := range ranks {
+		n[r]++
+	}
+	for _, c := range h {
+		if n[c.Rank] == 0 {
+			return false
+		}
+		n[c.Rank]--
+	}
+	return true
+}
+
+// valid returns true if the Charlie needs at least 5 cards and all ratios
+// are not negative.
+func (b Bonuses) valid() bool {
+	if b.Charlie != 0 && b.Charlie < 5 {
+		return false
+	}
+	for _, r := range []decimal.Decimal{b.CharlieRatio, b.Suited678, b.Triple7, b.FiveCard21} {
+		if r.Sign() < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bonus stops the play of bet b if its hand is a bonus hand. The bonus is
+// paid at once, unless the dealer may still have a blackjack that beats
+// it.
+func (g *game) bonus(b *bet) bool {
+	kind, _ := g.rules.Bonuses().Match(b.hand)
+	if kind == NoBonus {
+		return false
+	}
+
+	b.bonus = kind
+	if g.peeked || !g.upCardCanBlackjack() {
+		g.settleBet(Bonus, b)
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package blackjack
+
+import (
+	"testing"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+var testBonuses = Bonuses{
+	Charlie:      5,
+	CharlieRatio: decimal.New(2, 0),
+	Suited678:    decimal.New(3, 0),
+	Triple7:      decimal.New(5, 0),
+	FiveCard21:   decimal.New(4, 0),
+}
+
+func TestBonusesMatch(t *testing.T) {
+	cases := []struct {
+		bonuses Bonuses
+		hand    Hand
+		kind    BonusHand
+		ratio   int64
+	}{
+		{testBonuses, Hand{card.Spade(card.Six), card.Spade(card.Eight), card.Spade(card.Seven)}, Suited678, 3},
+		{testBonuses, Hand{card.Spade(card.Six), card.Heart(card.Seven), card.Spade(card.Eight)}, NoBonus, 0},
+		{testBonuses, Hand{card.Spade(card.Seven), card.Heart(card.Seven), card.Club(card.Seven)}, Triple7, 5},
+		{testBonuses, makeHand(card.Two, card.Three, card.Two, card.Four, card.Five), Charlie, 2},
+		{testBonuses, makeHand(card.Two, card.Three, card.Four, card.Five, card.Seven), FiveCard21, 4},
+		{testBonuses, makeHand(card.Two, card.Three, card.Four, card.Five, card.King), NoBonus, 0},
+		{testBonuses, makeHand(card.Two, card.Three, card.Four, card.Five), NoBonus, 0},
+		{Bonuses{Charlie: 6}, makeHand(card.Two, card.Three, card.Two, card.Four, card.Five), NoBonus, 0},
+		{Bonuses{Charlie: 6}, makeHand(card.Two, card.Three, card.Two, card.Four, card.Five, card.Ace), Charlie, 1},
+		{Bonuses{Charlie: 7}, makeHand(card.Ace, card.Ace, card.Two, card.Two, card.Three, card.Three, card.Four), Charlie, 1},
+		{Bonuses{}, Hand{card.Spade(card.Seven), card.Heart(card.Seven), card.Club(card.Seven)}, NoBonus, 0},
+	}
+
+	for _, c := range cases {
+		kind, ratio := c.bonuses.Match(c.hand)
+		if kind != c.kind || !ratio.Equal(decimal.New(c.ratio, 0)) {
+			t.Errorf("%v: got: %v %v, want: %v %v", c.hand, kind, ratio, c.kind, c.ratio)
+		}
+	}
+}
+
+func TestCharlie(t *testing.T) {
+	rules := testRules{bonuses: testBonuses}
+	dealer := Hand{card.Club(card.Four)}
+	player := Hand{
+		card.Heart(card.Five), card.Spade(card.Ace), card.Spade(card.Ace),
+		card.Diamond(card.Ace), card.Heart(card.Nine),
+	}
+
+	testPlay(t, 37, rules, 10, 0, []event{
+		hand{dealer, player[:2]},
+		nextAction{[]Action{Hit, Stand, Double}, Hit},
+		hand{dealer, player[:3]},
+		nextAction{[]Action{Hit, Stand}, Hit},
+		hand{dealer, player[:4]},
+		nextAction{[]Action{Hit, Stand}, Hit},
+		hand{dealer, player},
+		outcome{Bonus, decimal.New(30, 0), dealer, player},
+	})
+}
+
+func TestCharlieAfterDealer(t *testing.T) {
+	rules := testRules{bonuses: testBonuses}
+	dealer := Hand{card.Heart(card.Queen), card.Club(card.Five), card.Spade(card.Eight)}
+	player := Hand{
+		card.Spade(card.Ace), card.Spade(card.Two), card.Heart(card.Ace),
+		card.Diamond(card.Six), card.Heart(card.Nine),
+	}
+
+	testPlay(t, 3, rules, 10, 0, []event{
+		hand{dealer[:1], player[:2]},
+		nextAction{[]Action{Hit, Stand, Double}, Hit},
+		hand{dealer[:1], player[:3]},
+		nextAction{[]Action{Hit, Stand}, Hit},
+		hand{dealer[:1], player[:4]},
+		nextAction{[]Action{Hit, Stand}, Hit},
+		hand{dealer[:1], player},
+		dealerCard{dealer[1], dealer[:2]},
+		dealerCard{dealer[2], dealer},
+		outcome{Bonus, decimal.New(30, 0), dealer, player},
+	})
+}
+
+func TestCharlieDealerBlackjack(t *testing.T) {
+	rules := testRules{bonuses: testBonuses}
+	dealer := Hand{card.Spade(card.Queen), card.Diamond(card.Ace)}
+	player := Hand{
+		card.Club(card.Ace), card.Club(card.Eight), card.Club(card.Six),
+		card.Club(card.Ace), card.Club(card.Two),
+	}
+
+	testPlay(t, 46, rules, 10, 0, []event{
+		hand{dealer[:1], player[:2]},
+		nextAction{[]Action{Hit, Stand, Double}, Hit},
+		hand{dealer[:1], player[:3]},
+		nextAction{[]Action{Hit, Stand}, Hit},
+		hand{dealer[:1], player[:4]},
+		nextAction{[]Action{Hit, Stand}, Hit},
+		hand{dealer[:1], player},
+		dealerCard{dealer[1], dealer},
+		outcome{DealerBlackjack, decimal.New(10, 0), dealer, player},
+	})
+}
+
+// bonusUI records the bonus hands it is told.
+type bonusUI struct {
+	settleUI
+	kinds []BonusHand
+}
+
+func (ui *bonusUI) BonusOutcome(kind BonusHand, _ decimal.Decimal, _, _ Hand) {
+	ui.kinds = append(ui.kinds, kind)
+}
+
+func TestSettleBonus(t *testing.T) {
+	// A five card 21 settled as a Charlie is paid as a Charlie.
+	b := &bet{
+		hand:   makeHand(card.Two, card.Three, card.Four, card.Five, card.Seven),
+		amount: decimal.New(10, 0),
+		bonus:  Charlie,
+	}
+	f := player.NewFortune(decimal.Zero)
+	g := &game{
+		table:   &table{rules: testRules{bonuses: testBonuses}},
+		ui:      settleUI{},
+		fortune: f,
+		sum:     &Summary{},
+	}
+	g.settleBet(Bonus, b)
+
+	if want := decimal.New(30, 0); !f.Active().Equal(want) {
+		t.Errorf("got payout %v, want: %v", f.Active(), want)
+	}
+	e, ok := g.events[len(g.events)-1].(OutcomeEvent)
+	if !ok || e.Outcome != Bonus || e.Bonus != Charlie {
+		t.Fatalf("got event %+v, want: outcome %v of %v", g.events[len(g.events)-1], Bonus, Charlie)
+	}
+
+	var uis [MaxSeats]UI
+	ui := &bonusUI{}
+	uis[0] = ui
+	dispatch(uis, nil, e)
+	if len(ui.kinds) != 1 || ui.kinds[0] != Charlie {
+		t.Errorf("got bonus hands %v, want: %v", ui.kinds, Charlie)
+	}
+}
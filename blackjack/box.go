@@ -0,0 +1,176 @@
+package blackjack
+
+import (
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+// BoxUI is the UI of a player that plays several boxes at a table. Box is
+// called with the seat of a box before the callbacks that are about that
+// box, whenever it is another box than the one before. Dealer callbacks
+// are made once for the player and are not preceded by a call to Box.
+// After a round NewGame is asked once for all boxes, when the player
+// doesn't play another round all boxes leave the table.
+type BoxUI interface {
+	UI
+	Box(seat int)
+}
+
+// boxes is a player that plays several boxes with one UI and fortune.
+type boxes struct {
+	ui   BoxUI
+	seat int // box of the last callback
+}
+
+func (p *boxes) box(seat int) {
+	if p.seat != seat {
+		p.seat = seat
+		p.ui.Box(seat)
+	}
+}
+
+// boxUI is the UI of a single box of a player with several boxes.
+type boxUI struct {
+	*boxes
+	seat int
+}
+
+func (b boxUI) Bet(f *player.Fortune) decimal.Decimal {
+	b.box(b.seat)
+	return b.ui.Bet(f)
+}
+
+func (b boxUI) BetRejected(err *BetError) {
+	b.box(b.seat)
+	b.ui.BetRejected(err)
+}
+
+func (b boxUI) Hand(dealer DealerHand, player Hand) {
+	b.box(b.seat)
+	b.ui.Hand(dealer, player)
+}
+
+func (b boxUI) DealerPeek(bj bool)             { b.ui.DealerPeek(bj) }
+func (b boxUI) DealerReveal(h Hand)            { b.ui.DealerReveal(h) }
+func (b boxUI) DealerCard(c card.Card, h Hand) { b.ui.DealerCard(c, h) }
+
+func (b boxUI) NextAction(actions []Action) Action {
+	b.box(b.seat)
+	return b.ui.NextAction(actions)
+}
+
+func (b boxUI) SplitHand(left, right Hand, amount decimal.Decimal) {
+	b.box(b.seat)
+	b.ui.SplitHand(left, right, amount)
+}
+
+func (b boxUI) DoubleHand(h Hand, withdrawn decimal.Decimal) {
+	b.box(b.seat)
+	b.ui.DoubleHand(h, withdrawn)
+}
+
+func (b boxUI) Outcome(o Outcome, amount decimal.Decimal, dealer, player Hand) {
+	b.box(b.seat)
+	b.ui.Outcome(o, amount, dealer, player)
+}
+
+func (b boxUI) BonusOutcome(kind BonusHand, amount decimal.Decimal, dealer, player Hand) {
+	b.box(b.seat)
+	if bui, ok := b.ui.(BonusUI); ok {
+		bui.BonusOutcome(kind, amount, dealer, player)
+		return
+	}
+	b.ui.Outcome(Bonus, amount, dealer, player)
+}
+
+func (b boxUI) NewGame(f *player.Fortune) bool {
+	b.box(b.seat)
+	return b.ui.NewGame(f)
+}
+
+func (b boxUI) NoActiveFortune(f *player.Fortune) bool {
+	b.box(b.seat)
+	return b.ui.NoActiveFortune(f)
+}
+
+func (b boxUI) NoFortune() {
+	b.box(b.seat)
+	b.ui.NoFortune()
+}
+
+func (b boxUI) PerfectPairBet(f *player.Fortune) decimal.Decimal {
+	b.box(b.seat)
+	return b.ui.PerfectPairBet(f)
+}
+
+func (b boxUI) PerfectPair(kind PerfectPair, amount decimal.Decimal) {
+	b.box(b.seat)
+	b.ui.PerfectPair(kind, amount)
+}
+
+func (b boxUI) InsuranceBet(f *player.Fortune, max decimal.Decimal) decimal.Decimal {
+	b.box(b.seat)
+	return b.ui.InsuranceBet(f, max)
+}
+
+func (b boxUI) Insurance(won bool, amount decimal.Decimal) {
+	b.box(b.seat)
+	b.ui.Insurance(won, amount)
+}
+
+func (b boxUI) TakeEvenMoney() bool {
+	b.box(b.seat)
+	return b.ui.TakeEvenMoney()
+}
+
+// JoinBoxes seats a player at several seats, each seat is a box with an
+// independent bet that is dealt, played and settled on its own. All boxes
+// share the UI and fortune of the player.
+func (t *Table) JoinBoxes(ui BoxUI, f *player.Fortune, seats ...int) error {
+	if len(seats) == 0 {
+		return ErrNoSeat
+	}
+	for i, seat := range seats {
+		if seat < 0 || seat >= MaxSeats {
+			return ErrNoSeat
+		}
+		if t.seats[seat] != nil {
+			return ErrSeatTaken
+		}
+		for _, s := range seats[:i] {
+			if s == seat {
+				return ErrSeatTaken
+			}
+		}
+	}
+
+	p := &boxes{ui: ui, seat: -1}
+	sum := t.newSummary(seats[0])
+	for _, seat := range seats {
+		t.join(seat, boxUI{p, seat}, f, sum)
+	}
+	return nil
+}
+
+// roundUIs returns the UI of every player in games, a player with
+// several boxes is returned once.
+func roundUIs(games []*game) []UI {
+	var uis []UI
+	seen := make(map[*boxes]bool)
+	for _, g := range games {
+		b, ok := g.ui.(boxUI)
+		if !ok {
+			if g.ui != nil {
+				uis = append(uis, g.ui)
+			}
+			continue
+		}
+		if !seen[b.boxes] {
+			seen[b.boxes] = true
+			uis = append(uis, b.boxes.ui)
+		}
+	}
+	return uis
+}
@@ -0,0 +1,120 @@
+package blackjack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+type boxTestUI struct {
+	*testUI
+	boxes []int
+}
+
+func (ui *boxTestUI) Box(seat int) {
+	ui.boxes = append(ui.boxes, seat)
+}
+
+func TestBoxes(t *testing.T) {
+	rules := testRules{}
+	dealer := Hand{card.Diamond(card.Seven), card.Diamond(card.Jack)}
+	first := Hand{card.Club(card.Ten), card.Club(card.Queen)}
+	second := Hand{card.Club(card.Two), card.Club(card.Five), card.Spade(card.Nine)}
+
+	ui := &boxTestUI{testUI: &testUI{test: t, bet: 10, want: []event{
+		hand{dealer[:1], first},
+		nextAction{[]Action{Hit, Stand, Double}, Stand},
+		hand{dealer[:1], second[:2]},
+		nextAction{[]Action{Hit, Stand, Double}, Hit},
+		hand{dealer[:1], second},
+		nextAction{[]Action{Hit, Stand}, Stand},
+		dealerCard{dealer[1], dealer},
+		outcome{Won, decimal.New(20, 0), dealer, first},
+		outcome{Lost, decimal.New(-10, 0), dealer, second},
+	}}}
+
+	seededShuffler(27, func() {
+		ui.fort = player.NewFortune(decimal.New(50, 0))
+		tbl := NewTable(rules)
+		if err := tbl.JoinBoxes(ui, ui.fort, 2, 5); err != nil {
+			t.Fatal(err)
+		}
+		if err := tbl.Play(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// bet, play and settle both boxes and ask once for a new game
+	want := []int{2, 5, 2, 5, 2, 5, 2}
+	if !reflect.DeepEqual(ui.boxes, want) {
+		t.Errorf("got boxes %v, want: %v", ui.boxes, want)
+	}
+	if got, want := ui.fort.Active(), decimal.New(50, 0); !got.Equal(want) {
+		t.Errorf("got active fortune %v, want: %v", got, want)
+	}
+}
+
+func TestBoxesNewGame(t *testing.T) {
+	tbl := NewSeededTable(testRules{}, 1)
+	tbl.Sit(0, player.NewFortune(decimal.New(100, 0)))
+	tbl.JoinBoxes(nil, player.NewFortune(decimal.New(100, 0)), 2, 5)
+
+	var asked []int
+	for len(asked) < 2 {
+		s := tbl.State()
+		m := tbl.DefaultMove()
+		switch s.Phase {
+		case PhaseBet:
+			m.Amount = decimal.New(10, 0)
+		case PhaseNewGame:
+			asked = append(asked, s.Seat)
+			m.Accept = s.Seat == 0
+		case PhaseClosed:
+			t.Fatal("table closed")
+		}
+		mustApply(t, tbl, m)
+	}
+
+	if want := []int{0, 2}; !reflect.DeepEqual(asked, want) {
+		t.Errorf("got new game asked at seats %v, want: %v", asked, want)
+	}
+	if s := tbl.State(); s.Phase != PhaseBet || s.Seat != 0 {
+		t.Errorf("got state %v at seat %d, want: %v at seat 0", s.Phase, s.Seat, PhaseBet)
+	}
+	if tbl.seats[2] != nil || tbl.seats[5] != nil {
+		t.Error("boxes are seated after the player left")
+	}
+}
+
+func TestJoinBoxes(t *testing.T) {
+	tbl := NewTable(testRules{})
+	f := player.NewFortune(decimal.New(50, 0))
+	ui := &boxTestUI{testUI: &testUI{}}
+
+	if err := tbl.Join(3, &testUI{}, f); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		seats []int
+		err   error
+	}{
+		{[]int{0, MaxSeats}, ErrNoSeat},
+		{[]int{0, 3}, ErrSeatTaken},
+		{[]int{1, 1}, ErrSeatTaken},
+		{[]int{0, 1, 2}, nil},
+	}
+	for _, c := range cases {
+		if err := tbl.JoinBoxes(ui, f, c.seats...); err != c.err {
+			t.Errorf("seats %v: got error %v, want: %v", c.seats, err, c.err)
+		}
+	}
+
+	if n := tbl.Seated(); n != 4 {
+		t.Errorf("got %d seated players, want: 4", n)
+	}
+}
@@ -0,0 +1,58 @@
+package blackjack
+
+import (
+	"fmt"
+
+	"github.com/dwlnetnl/cards/card"
+)
+
+// DealerHand represents the dealer hand as seen by the player. Until the
+// hole card is revealed only the up-card is visible.
+type DealerHand struct {
+	hand     Hand
+	revealed bool
+}
+
+// UpCard returns the face-up card of the dealer, or the zero card when
+// the dealer has no cards yet.
+func (d DealerHand) UpCard() card.Card {
+	if len(d.hand) == 0 {
+		return card.Card{}
+	}
+	return d.hand[0]
+}
+
+// HasHoleCard returns true if the dealer has a face-down card.
+func (d DealerHand) HasHoleCard() bool { return !d.revealed && len(d.hand) > 1 }
+
+// Visible returns the face-up cards of the dealer.
+func (d DealerHand) Visible() Hand {
+	if d.HasHoleCard() {
+		return d.hand[:1:1]
+	}
+	return d.hand
+}
+
+func (d DealerHand) String() string {
+	if d.hand == nil {
+		return "<nil>"
+	}
+	v := d.Visible()
+	if d.HasHoleCard() {
+		return fmt.Sprintf("%s, ?? (%d)", v.Names(", "), v.Value().Total())
+	}
+	return v.String()
+}
+
+func (t *table) dealerHand() DealerHand {
+	return DealerHand{hand: t.dealer, revealed: t.revealed}
+}
+
+// reveal turns the hole card of the dealer face-up.
+func (t *table) reveal() {
+	if t.revealed || len(t.dealer) < 2 {
+		return
+	}
+	t.revealed = true
+	t.emit(DealerRevealEvent{Dealer: t.dealer})
+}
@@ -0,0 +1,44 @@
+package blackjack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dwlnetnl/cards/card"
+)
+
+func TestDealerHand(t *testing.T) {
+	h := Hand{card.Spade(card.King), card.Heart(card.Six)}
+
+	d := DealerHand{hand: h}
+	if !d.HasHoleCard() {
+		t.Error("hole card not hidden")
+	}
+	if got, want := d.Visible(), h[:1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got visible %v, want: %v", got, want)
+	}
+	if got, want := d.String(), "♤ K, ?? (10)"; got != want {
+		t.Errorf("got %q, want: %q", got, want)
+	}
+
+	d.revealed = true
+	if d.HasHoleCard() {
+		t.Error("revealed hole card is hidden")
+	}
+	if got := d.Visible(); !reflect.DeepEqual(got, h) {
+		t.Errorf("got visible %v, want: %v", got, h)
+	}
+	if got := d.UpCard(); got != h[0] {
+		t.Errorf("got up-card %v, want: %v", got, h[0])
+	}
+}
+
+func TestDealerHandEmpty(t *testing.T) {
+	var d DealerHand
+	if got := d.UpCard(); got != (card.Card{}) {
+		t.Errorf("got up-card %v, want: zero card", got)
+	}
+	if d.HasHoleCard() {
+		t.Error("empty hand has a hole card")
+	}
+}
@@ -0,0 +1,220 @@
+package blackjack
+
+import (
+	"github.com/dwlnetnl/cards/card"
+
+	"github.com/shopspring/decimal"
+)
+
+// Event is something that happened at a table as the result of a move.
+// Events with a seat concern the player at that seat, the dealer events
+// concern all players in the round.
+//
+// Apply returns the events that are reported to the UI of the players,
+// observers of the table receive these and the events that only tell
+// how the game went on, like the cards dealt and the actions taken.
+type Event interface {
+	event()
+}
+
+// DealerSeat is the seat of the dealer in events about cards.
+const DealerSeat = -1
+
+// BetRejectedEvent reports that a bet was rejected by the table limits.
+type BetRejectedEvent struct {
+	Seat int
+	Err  *BetError
+}
+
+// NoFortuneEvent reports that the player has no fortune left to bet with
+// and leaves the table.
+type NoFortuneEvent struct {
+	Seat int
+}
+
+// HandEvent reports the hand of the player that is in play.
+type HandEvent struct {
+	Seat   int
+	Dealer DealerHand
+	Player Hand
+}
+
+// DealerPeekEvent reports whether the dealer has blackjack after peeking.
+type DealerPeekEvent struct {
+	Blackjack bool
+}
+
+// DealerRevealEvent reports the dealer hand after revealing the hole card.
+// The dealer reveals it before drawing, so it comes before the dealer
+// cards and outcomes of the dealer's turn. A hand that is settled before,
+// like a surrender or a blackjack found by the peek, shows the hole card
+// for the first time in its outcome.
+type DealerRevealEvent struct {
+	Dealer Hand
+}
+
+// DealerCardEvent reports a card drawn by the dealer.
+type DealerCardEvent struct {
+	Card   card.Card
+	Dealer Hand
+}
+
+// SplitEvent reports a split hand and the amount bet on the new hand.
+type SplitEvent struct {
+	Seat        int
+	Left, Right Hand
+	Amount      decimal.Decimal
+}
+
+// DoubleEvent reports a doubled hand and the amount withdrawn for it.
+type DoubleEvent struct {
+	Seat      int
+	Hand      Hand
+	Withdrawn decimal.Decimal
+}
+
+// OutcomeEvent reports the settlement of a hand.
+type OutcomeEvent struct {
+	Seat    int
+	Outcome Outcome
+	Bonus   BonusHand // bonus hand of outcome Bonus
+	Amount  decimal.Decimal
+	Dealer  Hand
+	Player  Hand
+}
+
+// PerfectPairEvent reports a won perfect pair side bet.
+type PerfectPairEvent struct {
+	Seat   int
+	Kind   PerfectPair
+	Amount decimal.Decimal
+}
+
+// InsuranceEvent reports the settlement of an insurance bet.
+type InsuranceEvent struct {
+	Seat   int
+	Won    bool
+	Amount decimal.Decimal
+}
+
+// RoundStartEvent reports the start of a round with the players at seats,
+// rounds are numbered from 1. It is only sent to observers.
+type RoundStartEvent struct {
+	Round int
+	Seats []int
+}
+
+// RoundEndEvent reports the end of a round, after all bets are settled.
+// It is only sent to observers.
+type RoundEndEvent struct {
+	Round int
+}
+
+// BetEvent reports a bet that is placed. It is only sent to observers.
+type BetEvent struct {
+	Seat   int
+	Kind   BetKind
+	Amount decimal.Decimal
+}
+
+// CardEvent reports a card dealt from the shoe to a hand of the player at
+// seat, or to the dealer at DealerSeat. The hole card of the dealer is
+// dealt face-down and shows up in the DealerRevealEvent. It is only sent
+// to observers.
+type CardEvent struct {
+	Seat     int
+	Hand     int
+	Card     card.Card
+	FaceDown bool
+}
+
+// ActionEvent reports the action taken on a hand, including the actions
+// that are taken without asking the player. It is only sent to
+// observers.
+type ActionEvent struct {
+	Seat   int
+	Hand   int
+	Action Action
+}
+
+// MoveEvent reports a move made for the player at seat, with the actions
+// the player could choose from. Hand is the hand in play in PhaseAction.
+// It is only sent to observers.
+type MoveEvent struct {
+	Seat    int
+	Hand    int
+	Phase   Phase
+	Allowed []Action
+	Move    Move
+}
+
+// ShuffleEvent reports the cards of a round that are shuffled back into
+// the shoe. It is only sent to observers.
+type ShuffleEvent struct {
+	Cards []card.Card
+}
+
+func (RoundStartEvent) event()   {}
+func (RoundEndEvent) event()     {}
+func (BetEvent) event()          {}
+func (CardEvent) event()         {}
+func (ActionEvent) event()       {}
+func (MoveEvent) event()         {}
+func (ShuffleEvent) event()      {}
+func (BetRejectedEvent) event()  {}
+func (NoFortuneEvent) event()    {}
+func (HandEvent) event()         {}
+func (DealerPeekEvent) event()   {}
+func (DealerRevealEvent) event() {}
+func (DealerCardEvent) event()   {}
+func (SplitEvent) event()        {}
+func (DoubleEvent) event()       {}
+func (OutcomeEvent) event()      {}
+func (PerfectPairEvent) event()  {}
+func (InsuranceEvent) event()    {}
+
+// emit records event e of the current move and sends it to the
+// observers.
+func (t *table) emit(e Event) {
+	t.events = append(t.events, e)
+	t.notify(e)
+}
+
+// dispatch calls the UI method for event e. Seat events go to the UI at
+// the seat in uis, dealer events to the UIs of the players in round.
+func dispatch(uis [MaxSeats]UI, round []UI, e Event) {
+	switch e := e.(type) {
+	case BetRejectedEvent:
+		uis[e.Seat].BetRejected(e.Err)
+	case NoFortuneEvent:
+		uis[e.Seat].NoFortune()
+	case HandEvent:
+		uis[e.Seat].Hand(e.Dealer, e.Player)
+	case DealerPeekEvent:
+		for _, ui := range round {
+			ui.DealerPeek(e.Blackjack)
+		}
+	case DealerRevealEvent:
+		for _, ui := range round {
+			ui.DealerReveal(e.Dealer)
+		}
+	case DealerCardEvent:
+		for _, ui := range round {
+			ui.DealerCard(e.Card, e.Dealer)
+		}
+	case SplitEvent:
+		uis[e.Seat].SplitHand(e.Left, e.Right, e.Amount)
+	case DoubleEvent:
+		uis[e.Seat].DoubleHand(e.Hand, e.Withdrawn)
+	case OutcomeEvent:
+		if bui, ok := uis[e.Seat].(BonusUI); ok && e.Outcome == Bonus {
+			bui.BonusOutcome(e.Bonus, e.Amount, e.Dealer, e.Player)
+			break
+		}
+		uis[e.Seat].Outcome(e.Outcome, e.Amount, e.Dealer, e.Player)
+	case PerfectPairEvent:
+		uis[e.Seat].PerfectPair(e.Kind, e.Amount)
+	case InsuranceEvent:
+		uis[e.Seat].Insurance(e.Won, e.Amount)
+	}
+}
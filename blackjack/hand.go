@@ -24,47 +24,61 @@ func (h Hand) String() string {
 	if h == nil {
 		return "<nil>"
 	}
-	points, _ := h.Points()
-	return fmt.Sprintf("%s (%d)", h.Names(", "), points)
+	return fmt.Sprintf("%s (%d)", h.Names(", "), h.Value().Total())
 }
 
-var rankPoints = [...]int{
-	2, 3, 4, 5, 6, 7, 8,
-	9, 10, 10, 10, 10, 11,
+// HandValue represents the valuation of a blackjack hand.
+type HandValue struct {
+	Hard      int       // total with every ace counted as 1
+	Soft      int       // total with one ace counted as 11, if not bust
+	Cards     int       // number of cards in the hand
+	Pair      bool      // hand consists of two cards of equal rank
+	PairRank  card.Rank // rank of the pair, valid if Pair is true
+	Blackjack bool      // two card 21
+	Bust      bool      // hard total exceeds 21
 }
 
-// IsBlackjack returns true if hand h is a blackjack.
-func (h Hand) IsBlackjack() bool {
-	if len(h) != 2 {
-		return false
-	}
-	return rankPoints[h[0].Rank]+rankPoints[h[1].Rank] == 21
-}
+// Total returns the best total of hand value v.
+func (v HandValue) Total() int { return v.Soft }
 
-// Points returns the total number of points in hand h.
-// When hand h contains an ace that is counted as 11, soft is true.
-func (h Hand) Points() (total int, soft bool) {
-	aces := 0
+// IsSoft returns true if an ace is counted as 11.
+func (v HandValue) IsSoft() bool { return v.Soft != v.Hard }
+
+// Value returns the valuation of hand h.
+func (h Hand) Value() HandValue {
+	v := HandValue{Cards: len(h)}
+	aces := false
 
 	for _, c := range h {
-		total += rankPoints[c.Rank]
 		if c.Rank == card.Ace {
-			aces++
+			v.Hard++
+			aces = true
+		} else {
+			v.Hard += card.BlackjackPoints.Points(c)
 		}
 	}
 
-	if total <= 21 {
-		soft = true
-	} else {
-		if aces > 0 {
-			for i := 0; i < aces; i++ {
-				total = total - 10
-				if total < 21 {
-					break
-				}
-			}
-		}
+	v.Soft = v.Hard
+	if aces && v.Hard+10 <= 21 {
+		v.Soft += 10
 	}
 
-	return total, soft
+	if len(h) == 2 && h[0].Rank == h[1].Rank {
+		v.Pair = true
+		v.PairRank = h[0].Rank
+	}
+
+	v.Blackjack = len(h) == 2 && v.Soft == 21
+	v.Bust = v.Hard > 21
+	return v
+}
+
+// IsBlackjack returns true if hand h is a blackjack.
+func (h Hand) IsBlackjack() bool { return h.Value().Blackjack }
+
+// Points returns the total number of points in hand h.
+// When hand h contains an ace that is counted as 11, soft is true.
+func (h Hand) Points() (total int, soft bool) {
+	v := h.Value()
+	return v.Total(), v.IsSoft()
 }
@@ -44,7 +44,9 @@ func TestHandPoints(t *testing.T) {
 		{makeHand(card.Ace, card.Ten), 21, true},
 		{makeHand(card.Ace, card.Six, card.Four), 21, true},
 		{makeHand(card.Ace, card.Ace, card.Ten, card.Nine), 21, false},
-		{makeHand(card.Ace, card.Ace, card.Ace), 13, false},
+		{makeHand(card.Ace, card.Ace, card.Ace), 13, true},
+		{makeHand(card.Ace, card.Ace, card.Nine), 21, true},
+		{makeHand(card.Ten, card.Seven), 17, false},
 		{makeHand(card.Ace, card.Six), 17, true},
 		{makeHand(card.Ace, card.Six, card.Five), 12, false},
 		{makeHand(card.Ace, card.Seven, card.Nine), 17, false},
@@ -65,6 +67,102 @@ func TestHandPoints(t *testing.T) {
 	}
 }
 
+func TestHandValue(t *testing.T) {
+	cases := []struct {
+		in   Hand
+		want HandValue
+	}{
+		{makeHand(card.Ace, card.King), HandValue{
+			Hard: 11, Soft: 21, Cards: 2, Blackjack: true,
+		}},
+		{makeHand(card.Ace, card.Ace), HandValue{
+			Hard: 2, Soft: 12, Cards: 2, Pair: true, PairRank: card.Ace,
+		}},
+		{makeHand(card.Eight, card.Eight), HandValue{
+			Hard: 16, Soft: 16, Cards: 2, Pair: true, PairRank: card.Eight,
+		}},
+		{makeHand(card.Jack, card.Queen), HandValue{
+			Hard: 20, Soft: 20, Cards: 2,
+		}},
+		{makeHand(card.Ace, card.Ace, card.Nine), HandValue{
+			Hard: 11, Soft: 21, Cards: 3,
+		}},
+		{makeHand(card.Seven, card.Seven, card.Seven), HandValue{
+			Hard: 21, Soft: 21, Cards: 3,
+		}},
+		{makeHand(card.King, card.Queen, card.Two), HandValue{
+			Hard: 22, Soft: 22, Cards: 3, Bust: true,
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.in.Names(","), func(t *testing.T) {
+			got := c.in.Value()
+			if got != c.want {
+				t.Errorf("got: %+v, want: %+v", got, c.want)
+			}
+		})
+	}
+}
+
+// refValue values hand h by trying every combination of aces counted
+// as 1 or 11.
+func refValue(h Hand) (total int, soft bool) {
+	aces, base := 0, 0
+	for _, c := range h {
+		switch {
+		case c.Rank == card.Ace:
+			aces++
+		case c.Rank >= card.Ten:
+			base += 10
+		default:
+			base += int(c.Rank) + 2
+		}
+	}
+
+	total = base + aces
+	for elevens := 1; elevens <= aces; elevens++ {
+		t := base + aces + elevens*10
+		if t <= 21 {
+			total, soft = t, true
+		}
+	}
+	return total, soft
+}
+
+func TestHandValueExhaustive(t *testing.T) {
+	check := func(h Hand) {
+		v := h.Value()
+		total, soft := refValue(h)
+		if v.Total() != total || v.IsSoft() != soft {
+			t.Errorf("[%s] = %d (soft: %v), want: %d (soft: %v)",
+				h.Names(", "), v.Total(), v.IsSoft(), total, soft)
+		}
+		if v.Cards != len(h) {
+			t.Errorf("[%s] cards = %d, want: %d", h.Names(", "), v.Cards, len(h))
+		}
+		if bust := total > 21; v.Bust != bust {
+			t.Errorf("[%s] bust = %v, want: %v", h.Names(", "), v.Bust, bust)
+		}
+		if bj := len(h) == 2 && total == 21; v.Blackjack != bj {
+			t.Errorf("[%s] blackjack = %v, want: %v", h.Names(", "), v.Blackjack, bj)
+		}
+		pair := len(h) == 2 && h[0].Rank == h[1].Rank
+		if v.Pair != pair || pair && v.PairRank != h[0].Rank {
+			t.Errorf("[%s] pair = %v (%v), want: %v", h.Names(", "), v.Pair, v.PairRank, pair)
+		}
+	}
+
+	for r1 := card.Two; r1 <= card.Ace; r1++ {
+		for r2 := card.Two; r2 <= card.Ace; r2++ {
+			check(makeHand(r1, r2))
+			for r3 := card.Two; r3 <= card.Ace; r3++ {
+				check(makeHand(r1, r2, r3))
+			}
+		}
+	}
+}
+
 func TestHandPerfectPair(t *testing.T) {
 	cases := []struct {
 		in   Hand
@@ -13,18 +13,59 @@ import (
 
 func seededShuffler(seed int64, fn func()) {
 	old := newShuffler
-	newShuffler = func(d card.Deck, num uint) *card.Shuffler {
+	newShuffler = func(d card.Deck, num uint, _ rand.Source) *card.Shuffler {
 		return card.NewSeededShuffler(d, num, rand.NewSource(seed))
 	}
 	fn()
 	newShuffler = old
 }
 
+// playRandom plays rounds at table tbl with random moves, the players
+// leave after the given number of rounds.
+func playRandom(t *testing.T, tbl *Table, rnd *rand.Rand, rounds int) {
+	t.Helper()
+	for n := 0; tbl.State().Phase != PhaseClosed; n++ {
+		if n > 100*rounds {
+			t.Fatalf("session doesn't end: %+v", tbl.State())
+		}
+		mustApply(t, tbl, randomMove(tbl, rnd, rounds))
+	}
+}
+
+// randomMove returns a random move for table tbl, the players leave after
+// the given number of rounds.
+func randomMove(tbl *Table, rnd *rand.Rand, rounds int) Move {
+	var m Move
+	switch s := tbl.State(); s.Phase {
+	case PhaseBet:
+		m.Amount = decimal.New(10, 0)
+	case PhaseNewGame:
+		m.Accept = tbl.rounds < rounds-1
+	case PhasePerfectPairBet:
+		m.Amount = decimal.New(5, 0)
+	case PhaseInsuranceBet:
+		m.Amount = s.MaxInsurance
+	case PhaseEvenMoney:
+		m.Accept = rnd.Intn(2) == 0
+	case PhaseAction:
+		actions := tbl.LegalActions()
+		m.Action = actions[rnd.Intn(len(actions))]
+	}
+	return m
+}
+
 func testPlay(t *testing.T, seed int64, rules Rules, bet, pp int64, want []event) {
+	testPlayUI(t, seed, rules, &testUI{want: want, bet: bet, pp: pp})
+}
+
+func testPlayUI(t *testing.T, seed int64, rules Rules, ui *testUI) {
 	seededShuffler(seed, func() {
 		f := player.NewFortune(decimal.New(50, 0))
-		ui := &testUI{test: t, want: want, fort: f, bet: bet, pp: pp}
-		Play(ui, rules, f)
+		ui.test = t
+		ui.fort = f
+		if _, err := Play(ui, rules, f); err != nil {
+			t.Fatal(err)
+		}
 	})
 }
 
@@ -33,8 +74,12 @@ type testUI struct {
 	want []event
 	idx  int
 	end  bool
+	bets []int64 // bets placed before bet
 	bet  int64
 	pp   int64
+	inss []int64 // insurance bets placed before ins
+	ins  int64
+	even bool
 	bal  decimal.Decimal
 	fort *player.Fortune
 }
@@ -75,13 +120,33 @@ func (ui *testUI) Bet(fortune *player.Fortune) decimal.Decimal {
 	if fortune != ui.fort {
 		ui.test.Fatalf("got fortune %v, want: %v", fortune, ui.fort)
 	}
-	amount := decimal.New(ui.bet, 0)
+	bet := ui.bet
+	if len(ui.bets) > 0 {
+		bet, ui.bets = ui.bets[0], ui.bets[1:]
+	}
+	amount := decimal.New(bet, 0)
 	ui.bal = fortune.Active().Sub(amount)
 	return amount
 }
 
-func (ui *testUI) Hand(dealer, player Hand) {
-	ui.check(hand{dealer, player})
+func (ui *testUI) BetRejected(err *BetError) {
+	ui.check(betRejected{*err})
+	switch err.Kind {
+	case SideBet, InsuranceBet: // subtracted when the bet was asked
+		ui.bal = ui.bal.Add(err.Amount)
+	}
+}
+
+func (ui *testUI) Hand(dealer DealerHand, player Hand) {
+	ui.check(hand{dealer.Visible(), player})
+}
+
+func (ui *testUI) DealerPeek(blackjack bool) {
+	ui.check(dealerPeek{blackjack})
+}
+
+func (ui *testUI) DealerReveal(hand Hand) {
+	ui.check(dealerReveal{hand})
 }
 
 func (ui *testUI) DealerCard(card card.Card, hand Hand) {
@@ -112,7 +177,8 @@ func (ui *testUI) DoubleHand(hand Hand, amount decimal.Decimal) {
 
 func (ui *testUI) Outcome(out Outcome, amount decimal.Decimal, dealer, player Hand) {
 	ui.check(outcome{out, amount, dealer, player})
-	if out == Won || out == Pushed || out == Blackjack || out == Surrendered {
+	switch out {
+	case Won, Pushed, Blackjack, Surrendered, EvenMoney, Bonus:
 		ui.bal = ui.bal.Add(amount)
 	}
 	ui.end = true
@@ -127,6 +193,9 @@ func (ui *testUI) NewGame(fortune *player.Fortune) bool {
 			ui.test.Error("game had no outcome")
 			ui.test.Fail()
 		}
+		if ui.idx != len(ui.want) {
+			ui.test.Errorf("got %d events, want: %d", ui.idx, len(ui.want))
+		}
 		if !ui.bal.Equal(ui.fort.Active()) {
 			ui.test.Errorf("got balance %v, want: %v", ui.bal, ui.fort.Active())
 			ui.test.Fail()
@@ -157,6 +226,32 @@ func (ui *testUI) PerfectPair(kind PerfectPair, amount decimal.Decimal) {
 	ui.bal = ui.bal.Add(amount)
 }
 
+func (ui *testUI) InsuranceBet(fortune *player.Fortune, max decimal.Decimal) decimal.Decimal {
+	if fortune != ui.fort {
+		ui.test.Fatalf("got fortune %v, want: %v", fortune, ui.fort)
+	}
+	ui.check(insuranceBet{max})
+	ins := ui.ins
+	if len(ui.inss) > 0 {
+		ins, ui.inss = ui.inss[0], ui.inss[1:]
+	}
+	amount := decimal.New(ins, 0)
+	ui.bal = ui.bal.Sub(amount)
+	return amount
+}
+
+func (ui *testUI) Insurance(won bool, amount decimal.Decimal) {
+	ui.check(insurance{won, amount})
+	if won {
+		ui.bal = ui.bal.Add(amount)
+	}
+}
+
+func (ui *testUI) TakeEvenMoney() bool {
+	ui.check(evenMoney{})
+	return ui.even
+}
+
 type event interface {
 	test(t *testing.T, num int, other event)
 }
@@ -178,6 +273,48 @@ func (want hand) test(t *testing.T, num int, other event) {
 	}
 }
 
+type betRejected struct {
+	err BetError
+}
+
+func (want betRejected) test(t *testing.T, num int, other event) {
+	got := other.(betRejected)
+	if got.err.Kind != want.err.Kind || got.err.Problem != want.err.Problem {
+		t.Errorf("#%d: got rejected %v %v, want: %v %v", num,
+			got.err.Kind, got.err.Problem, want.err.Kind, want.err.Problem)
+		t.Fail()
+	}
+	if !got.err.Amount.Equal(want.err.Amount) || !got.err.Limit.Equal(want.err.Limit) {
+		t.Errorf("#%d: got amount %v limit %v, want: %v limit %v", num,
+			got.err.Amount, got.err.Limit, want.err.Amount, want.err.Limit)
+		t.Fail()
+	}
+}
+
+type dealerPeek struct {
+	blackjack bool
+}
+
+func (want dealerPeek) test(t *testing.T, num int, other event) {
+	got := other.(dealerPeek)
+	if got.blackjack != want.blackjack {
+		t.Errorf("#%d: got blackjack %v, want: %v", num, got.blackjack, want.blackjack)
+		t.Fail()
+	}
+}
+
+type dealerReveal struct {
+	hand Hand
+}
+
+func (want dealerReveal) test(t *testing.T, num int, other event) {
+	got := other.(dealerReveal)
+	if !reflect.DeepEqual(got.hand, want.hand) {
+		t.Errorf("#%d: got hand %v, want: %v", num, got.hand, want.hand)
+		t.Fail()
+	}
+}
+
 type dealerCard struct {
 	card card.Card
 	hand Hand
@@ -293,3 +430,41 @@ func (want noFortune) test(t *testing.T, num int, other event) {
 		t.Fatalf("#%d: got %v, want: %v", num, got, want)
 	}
 }
+
+type insuranceBet struct {
+	max decimal.Decimal
+}
+
+func (want insuranceBet) test(t *testing.T, num int, other event) {
+	got := other.(insuranceBet)
+	if !got.max.Equal(want.max) {
+		t.Errorf("#%d: got max %v, want: %v", num, got.max, want.max)
+		t.Fail()
+	}
+}
+
+type insurance struct {
+	won    bool
+	amount decimal.Decimal
+}
+
+func (want insurance) test(t *testing.T, num int, other event) {
+	got := other.(insurance)
+	if got.won != want.won {
+		t.Errorf("#%d: got won %v, want: %v", num, got.won, want.won)
+		t.Fail()
+	}
+	if !got.amount.Equal(want.amount) {
+		t.Errorf("#%d: got amount %v, want: %v", num, got.amount, want.amount)
+		t.Fail()
+	}
+}
+
+type evenMoney struct{}
+
+func (want evenMoney) test(t *testing.T, num int, other event) {
+	got := other.(evenMoney)
+	if got != want {
+		t.Fatalf("#%d: got %v, want: %v", num, got, want)
+	}
+}
@@ -0,0 +1,343 @@
+package blackjack
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+// HandHistory is the history of a round at a table. It is written by a
+// HistoryWriter as one line of JSON. Bets that are returned because the
+// table stopped before the round was dealt, are in the results of the
+// next round. The history of a round that is in play when the recording
+// starts, like at a resumed table, misses the cards and moves before.
+type HandHistory struct {
+	Round   int             `json:"round"`
+	Time    time.Time       `json:"time"`            // start of the round
+	Name    string          `json:"name,omitempty"`  // name of the rules, see RulesName
+	Rules   string          `json:"rules"`           // in the notation of FormatRules
+	Seed    int64           `json:"seed"`            // seed of the shoe
+	Seats   []int           `json:"seats"`           // seats of the players in the round
+	Boxes   [][]int         `json:"boxes,omitempty"` // seats of players with several boxes
+	Cards   []HistoryCard   `json:"cards"`
+	Moves   []HistoryMove   `json:"moves"`
+	Results []HistoryResult `json:"results"`
+}
+
+// GameRules returns the rules of the game of round h: the available rules
+// with the recorded name, otherwise the rules of the recorded notation.
+func (h *HandHistory) GameRules() (Rules, error) {
+	if r, ok := LookupRules(h.Name); ok && FormatRules(r) == h.Rules {
+		return r, nil
+	}
+	return ParseRules(h.Rules)
+}
+
+// HistoryCard is a card dealt from the shoe, in the order it was dealt.
+type HistoryCard struct {
+	Seat     int       `json:"seat"` // DealerSeat for the dealer
+	Hand     int       `json:"hand"`
+	Card     card.Card `json:"card"`
+	FaceDown bool      `json:"faceDown,omitempty"`
+}
+
+// HistoryMove is a move made for a player. The moves of a round are the
+// moves made since the previous round, like the bets and whether the
+// players play another round. Fortune is the total fortune of the player
+// before the move.
+type HistoryMove struct {
+	Seat    int             `json:"seat"`
+	Hand    int             `json:"hand"`
+	Phase   Phase           `json:"phase"`
+	Allowed []Action        `json:"allowed,omitempty"`
+	Action  Action          `json:"action"`
+	Amount  decimal.Decimal `json:"amount"`
+	Accept  bool            `json:"accept"`
+	Fortune decimal.Decimal `json:"fortune"`
+}
+
+// Move returns the move that was made.
+func (m HistoryMove) Move() Move {
+	return Move{Action: m.Action, Amount: m.Amount, Accept: m.Accept}
+}
+
+// HistoryResult is the settlement of a bet, MainBet for a hand, SideBet
+// for a perfect pair bet or InsuranceBet. Amount is the amount of the
+// outcome as it is reported to the UI, Fortune is the total fortune of
+// the player after the bet is settled.
+type HistoryResult struct {
+	Seat    int             `json:"seat"`
+	Bet     BetKind         `json:"bet"`
+	Outcome Outcome         `json:"outcome"`
+	Hand    Hand            `json:"hand,omitempty"`
+	Amount  decimal.Decimal `json:"amount"`
+	Fortune decimal.Decimal `json:"fortune"`
+}
+
+// HistoryWriter writes the hand history of a table in the JSON Lines
+// format, every round is written as a HandHistory on its own line when
+// the round ends.
+type HistoryWriter struct {
+	rec         recorder
+	enc         *json.Encoder
+	err         error
+	unsubscribe func()
+}
+
+// NewHistoryWriter returns a HistoryWriter that records the rounds played
+// at table t to w.
+func NewHistoryWriter(w io.Writer, t *Table) *HistoryWriter {
+	hw := &HistoryWriter{enc: json.NewEncoder(w)}
+	hw.rec = recorder{t: t, done: hw.write}
+	hw.unsubscribe = t.Subscribe(ObserverFunc(hw.rec.observe))
+	return hw
+}
+
+// Close stops recording the history of the table, a round that is not
+// finished is not written. It returns the first error that occurred
+// while writing.
+func (hw *HistoryWriter) Close() error {
+	hw.unsubscribe()
+	return hw.err
+}
+
+// Err returns the first error that occurred while writing.
+func (hw *HistoryWriter) Err() error {
+	return hw.err
+}
+
+func (hw *HistoryWriter) write(h *HandHistory) {
+	if hw.err == nil {
+		hw.err = hw.enc.Encode(h)
+	}
+}
+
+// recorder records the history of the rounds at a table from its events.
+type recorder struct {
+	t    *Table
+	h    *HandHistory // round in play
+	pp   map[int]int  // result of the perfect pair bet at a seat
+	done func(h *HandHistory)
+}
+
+func (r *recorder) observe(e Event) {
+	if r.h == nil {
+		r.h = &HandHistory{Rules: FormatRules(r.t.rules), Seed: r.t.seed}
+		if name := RulesName(r.t.rules); name != r.h.Rules {
+			r.h.Name = name
+		}
+		r.pp = make(map[int]int)
+		if r.t.step >= stepPerfectPair && r.t.step <= stepPlay {
+			r.start(r.t.rounds+1, r.t.seatsInRound())
+		}
+	}
+	h := r.h
+
+	switch e := e.(type) {
+	case RoundStartEvent:
+		r.start(e.Round, e.Seats)
+	case CardEvent:
+		h.Cards = append(h.Cards, HistoryCard{Seat: e.Seat, Hand: e.Hand, Card: e.Card, FaceDown: e.FaceDown})
+	case MoveEvent:
+		h.Moves = append(h.Moves, HistoryMove{
+			Seat:    e.Seat,
+			Hand:    e.Hand,
+			Phase:   e.Phase,
+			Allowed: e.Allowed,
+			Action:  e.Move.Action,
+			Amount:  e.Move.Amount,
+			Accept:  e.Move.Accept,
+			Fortune: r.fortune(e.Seat),
+		})
+	case BetEvent:
+		// A perfect pair bet is settled at once and only reported when
+		// it is won.
+		if e.Kind == SideBet {
+			r.pp[e.Seat] = len(h.Results)
+			r.result(e.Seat, SideBet, Lost, nil, e.Amount.Neg())
+		}
+	case PerfectPairEvent:
+		res := &h.Results[r.pp[e.Seat]]
+		res.Outcome, res.Amount, res.Fortune = Won, e.Amount, r.fortune(e.Seat)
+	case InsuranceEvent:
+		o := Lost
+		if e.Won {
+			o = Won
+		}
+		r.result(e.Seat, InsuranceBet, o, nil, e.Amount)
+	case OutcomeEvent:
+		r.result(e.Seat, MainBet, e.Outcome, e.Player, e.Amount)
+	case RoundEndEvent:
+		r.h = nil
+		r.done(h)
+	}
+}
+
+// start starts the history of round with the players at seats.
+func (r *recorder) start(round int, seats []int) {
+	r.h.Round = round
+	r.h.Time = time.Now()
+	r.h.Seats = seats
+	r.h.Boxes = r.t.boxes()
+}
+
+func (r *recorder) result(seat int, kind BetKind, o Outcome, h Hand, amount decimal.Decimal) {
+	r.h.Results = append(r.h.Results, HistoryResult{
+		Seat:    seat,
+		Bet:     kind,
+		Outcome: o,
+		Hand:    h,
+		Amount:  amount,
+		Fortune: r.fortune(seat),
+	})
+}
+
+// fortune returns the total fortune of the player at seat.
+func (r *recorder) fortune(seat int) decimal.Decimal {
+	if f := r.t.fortune(seat); f != nil {
+		return f.Total()
+	}
+	return decimal.Zero
+}
+
+// boxes returns the seats of every seated player with several boxes.
+func (t *Table) boxes() [][]int {
+	var boxes [][]int
+	seen := make(map[*Summary]bool)
+	for i, g := range t.seats {
+		if g == nil || seen[g.sum] {
+			continue
+		}
+		seen[g.sum] = true
+
+		seats := []int{i}
+		for j := i + 1; j < MaxSeats; j++ {
+			if other := t.seats[j]; other != nil && other.sum == g.sum {
+				seats = append(seats, j)
+			}
+		}
+		if len(seats) > 1 {
+			boxes = append(boxes, seats)
+		}
+	}
+	return boxes
+}
+
+// fortune returns the fortune of the player at seat, including a player
+// in the round that left the seat.
+func (t *Table) fortune(seat int) *player.Fortune {
+	for _, g := range t.games {
+		if g.seat == seat {
+			return g.fortune
+		}
+	}
+	if g := t.seats[seat]; g != nil {
+		return g.fortune
+	}
+	return nil
+}
+
+// maxHistoryLine is the maximum length of a line of a hand history.
+const maxHistoryLine = 1 << 20
+
+// HistoryReader reads a hand history written by a HistoryWriter.
+type HistoryReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewHistoryReader returns a HistoryReader that reads from r.
+func NewHistoryReader(r io.Reader) *HistoryReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, maxHistoryLine)
+	return &HistoryReader{s: s}
+}
+
+// Read reads the history of the next round, it returns io.EOF when there
+// are no rounds left. Empty lines are skipped.
+func (hr *HistoryReader) Read() (*HandHistory, error) {
+	for hr.s.Scan() {
+		hr.line++
+		line := hr.s.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		h := &HandHistory{}
+		if err := json.Unmarshal(line, h); err != nil {
+			return nil, fmt.Errorf("blackjack: hand history line %d: %v", hr.line, err)
+		}
+		return h, nil
+	}
+	if err := hr.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ReadHistory reads all rounds of the hand history in r.
+func ReadHistory(r io.Reader) ([]*HandHistory, error) {
+	hr := NewHistoryReader(r)
+	var hs []*HandHistory
+	for {
+		h, err := hr.Read()
+		if err == io.EOF {
+			return hs, nil
+		}
+		if err != nil {
+			return hs, err
+		}
+		hs = append(hs, h)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (a Action) MarshalText() ([]byte, error) { return []byte(a.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *Action) UnmarshalText(text []byte) error {
+	i, err := parseOption("action", string(text), len(_Action_index)-1,
+		func(i int) string { return Action(i).String() })
+	*a = Action(i)
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (o Outcome) MarshalText() ([]byte, error) { return []byte(o.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (o *Outcome) UnmarshalText(text []byte) error {
+	i, err := parseOption("outcome", string(text), len(_Outcome_index)-1,
+		func(i int) string { return Outcome(i).String() })
+	*o = Outcome(i)
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p Phase) MarshalText() ([]byte, error) { return []byte(p.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Phase) UnmarshalText(text []byte) error {
+	i, err := parseOption("phase", string(text), len(_Phase_index)-1,
+		func(i int) string { return Phase(i).String() })
+	*p = Phase(i)
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k BetKind) MarshalText() ([]byte, error) { return []byte(k.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (k *BetKind) UnmarshalText(text []byte) error {
+	i, err := parseOption("bet kind", string(text), len(_BetKind_index)-1,
+		func(i int) string { return BetKind(i).String() })
+	*k = BetKind(i)
+	return err
+}
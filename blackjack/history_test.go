@@ -0,0 +1,131 @@
+package blackjack
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestHistory(t *testing.T) {
+	tbl := NewSeededTable(testRules{}, 27)
+	f0 := player.NewFortune(decimal.New(50, 0))
+	f1 := player.NewFortune(decimal.New(50, 0))
+	tbl.Sit(1, f0)
+	tbl.Sit(4, f1)
+
+	var buf bytes.Buffer
+	hw := NewHistoryWriter(&buf, tbl)
+	moves := []Move{
+		{Amount: decimal.New(10, 0)},
+		{Amount: decimal.New(10, 0)},
+		{Action: Stand},
+		{Action: Hit},
+		{Action: Stand},
+		{Accept: true},
+		{Accept: false},
+		{Amount: decimal.New(5, 0)},
+	}
+	for _, m := range moves {
+		mustApply(t, tbl, m)
+	}
+	if err := hw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"card":"QC"`) {
+		t.Errorf("got history %s, want cards like QC", buf.String())
+	}
+
+	hs, err := ReadHistory(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hs) != 1 {
+		t.Fatalf("got %d rounds, want: 1", len(hs))
+	}
+
+	h := hs[0]
+	if h.Round != 1 || h.Seed != 27 || h.Rules != FormatRules(testRules{}) || h.Time.IsZero() {
+		t.Errorf("got round %d seed %d rules %q at %v, want: round 1 seed 27 rules %q",
+			h.Round, h.Seed, h.Rules, h.Time, FormatRules(testRules{}))
+	}
+	if !reflect.DeepEqual(h.Seats, []int{1, 4}) {
+		t.Errorf("got seats %v, want: [1 4]", h.Seats)
+	}
+
+	wantCards := []HistoryCard{
+		{Seat: 1, Card: card.Club(card.Ten)},
+		{Seat: 4, Card: card.Club(card.Two)},
+		{Seat: DealerSeat, Card: card.Diamond(card.Seven)},
+		{Seat: 1, Card: card.Club(card.Queen)},
+		{Seat: 4, Card: card.Club(card.Five)},
+		{Seat: 4, Card: card.Spade(card.Nine)},
+		{Seat: DealerSeat, Card: card.Diamond(card.Jack)},
+	}
+	if !reflect.DeepEqual(h.Cards, wantCards) {
+		t.Errorf("got cards %v, want: %v", h.Cards, wantCards)
+	}
+
+	wantMoves := []struct {
+		seat    int
+		phase   Phase
+		allowed []Action
+		move    Move
+	}{
+		{1, PhaseBet, nil, moves[0]},
+		{4, PhaseBet, nil, moves[1]},
+		{1, PhaseAction, []Action{Hit, Stand, Double}, moves[2]},
+		{4, PhaseAction, []Action{Hit, Stand, Double}, moves[3]},
+		{4, PhaseAction, []Action{Hit, Stand}, moves[4]},
+	}
+	if len(h.Moves) != len(wantMoves) {
+		t.Fatalf("got moves %v, want: %v", h.Moves, wantMoves)
+	}
+	for i, want := range wantMoves {
+		m := h.Moves[i]
+		got := m.Move()
+		if m.Seat != want.seat || m.Phase != want.phase || !reflect.DeepEqual(m.Allowed, want.allowed) ||
+			got.Action != want.move.Action || !got.Amount.Equal(want.move.Amount) || got.Accept != want.move.Accept {
+			t.Errorf("#%d: got move %+v, want: %+v", i+1, m, want)
+		}
+	}
+
+	wantResults := []struct {
+		seat    int
+		outcome Outcome
+		amount  int64
+		fortune int64
+	}{
+		{1, Won, 20, 60},
+		{4, Lost, -10, 40},
+	}
+	if len(h.Results) != len(wantResults) {
+		t.Fatalf("got results %v, want: %v", h.Results, wantResults)
+	}
+	for i, want := range wantResults {
+		r := h.Results[i]
+		if r.Seat != want.seat || r.Bet != MainBet || r.Outcome != want.outcome ||
+			!r.Amount.Equal(decimal.New(want.amount, 0)) || !r.Fortune.Equal(decimal.New(want.fortune, 0)) {
+			t.Errorf("#%d: got result %+v, want: %+v", i+1, r, want)
+		}
+	}
+	if len(h.Results[0].Hand) != 2 || len(h.Results[1].Hand) != 3 {
+		t.Errorf("got hands %v and %v, want: 2 and 3 cards", h.Results[0].Hand, h.Results[1].Hand)
+	}
+}
+
+func TestHistoryReadError(t *testing.T) {
+	r := NewHistoryReader(strings.NewReader("{\"round\":1}\n\n{\"round\":\"two\"}\n"))
+	if h, err := r.Read(); err != nil || h.Round != 1 {
+		t.Fatalf("got round %v and error %v, want: round 1", h, err)
+	}
+	_, err := r.Read()
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("got error %v, want error on line 3", err)
+	}
+}
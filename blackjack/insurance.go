@@ -0,0 +1,77 @@
+package blackjack
+
+import (
+	"github.com/dwlnetnl/cards/card"
+
+	"github.com/shopspring/decimal"
+)
+
+// offerInsurance returns true if insurance, or even money to a player
+// with a blackjack, is offered when the dealer shows an ace. Insurance
+// isn't offered when the maximum insurance bet rounds down to zero.
+func (g *game) offerInsurance() bool {
+	if !g.rules.Insurance() || g.dealer[0].Rank != card.Ace || !g.playing() {
+		return false
+	}
+	if g.bets[0].hand.IsBlackjack() && g.rules.EvenMoney() {
+		return true
+	}
+	return g.maxInsurance().Sign() > 0
+}
+
+// takeEvenMoney settles the blackjack of the player at even money if
+// the player accepts it.
+func (g *game) takeEvenMoney(accept bool) {
+	if accept {
+		g.settleBet(EvenMoney, g.bets[0])
+	}
+}
+
+// insure places a valid insurance bet of amount, a zero amount declines
+// insurance.
+func (g *game) insure(amount decimal.Decimal) {
+	if amount.Cmp(decimal.Zero) <= 0 {
+		return
+	}
+
+	g.fortune.Withdrawal(amount)
+	g.insured = amount
+	g.notify(BetEvent{Seat: g.seat, Kind: InsuranceBet, Amount: amount})
+}
+
+// maxInsurance returns the maximum insurance bet, half the bet. It is
+// rounded down to the chip unit unless payouts are exact.
+func (g *game) maxInsurance() decimal.Decimal {
+	max := g.bets[0].amount.Div(decimal.New(2, 0))
+	if l := g.rules.Limits(); l.Rounding != RoundExact {
+		max = RoundDown.round(max, l.Unit)
+	}
+	return max
+}
+
+// settleInsurance pays the insurance bet, 2:1 by default, if the dealer
+// has blackjack.
+// It is settled when the dealer peeks or otherwise after play, without a
+// hole card the dealer draws its second card to settle it.
+func (g *game) settleInsurance() {
+	if g.insured.Equal(decimal.Zero) {
+		return
+	}
+
+	if len(g.dealer) == 1 {
+		g.drawDealer()
+	}
+
+	stake := g.insured
+	g.insured = decimal.Zero
+
+	if g.dealer.IsBlackjack() {
+		amount := g.payout(stake, stake.Mul(g.rules.Payouts().insurance()))
+		g.fortune.Deposit(amount)
+		g.sum.side(&g.sum.Insurance, stake, amount)
+		g.emit(InsuranceEvent{Seat: g.seat, Won: true, Amount: amount})
+	} else {
+		g.sum.side(&g.sum.Insurance, stake, decimal.Zero)
+		g.emit(InsuranceEvent{Seat: g.seat, Won: false, Amount: decimal.Zero.Sub(stake)})
+	}
+}
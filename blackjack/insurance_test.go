@@ -0,0 +1,178 @@
+package blackjack
+
+import (
+	"testing"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestInsurance(t *testing.T) {
+	rules := testRules{holeCard: true, insurance: true, peek: PeekAceTen}
+
+	bothBlackjack := struct{ dealer, player Hand }{
+		Hand{card.Heart(card.Ace), card.Club(card.Queen)},
+		Hand{card.Diamond(card.King), card.Spade(card.Ace)},
+	}
+	dealerBlackjack := struct{ dealer, player Hand }{
+		Hand{card.Heart(card.Ace), card.Heart(card.Ten)},
+		Hand{card.Heart(card.Jack), card.Heart(card.Queen)},
+	}
+	playerBlackjack := struct{ dealer, player Hand }{
+		Hand{card.Spade(card.Ace), card.Heart(card.Seven)},
+		Hand{card.Heart(card.Ace), card.Heart(card.Jack)},
+	}
+	noBlackjack := struct{ dealer, player Hand }{
+		Hand{card.Spade(card.Ace), card.Diamond(card.Nine)},
+		Hand{card.Heart(card.Eight), card.Spade(card.Nine)},
+	}
+
+	cases := []struct {
+		name string
+		seed int64
+		ins  []int64
+		even bool
+		want []event
+	}{
+		{"BothBlackjack/EvenMoney", 301, nil, true, []event{
+			evenMoney{},
+			outcome{EvenMoney, decimal.New(20, 0), bothBlackjack.dealer, bothBlackjack.player},
+		}},
+		{"BothBlackjack/NoEvenMoney", 301, nil, false, []event{
+			evenMoney{},
+			dealerPeek{true},
+			outcome{Pushed, decimal.New(10, 0), bothBlackjack.dealer, bothBlackjack.player},
+		}},
+		{"DealerBlackjack/Insured", 1, []int64{5}, false, []event{
+			insuranceBet{decimal.New(5, 0)},
+			dealerPeek{true},
+			insurance{true, decimal.New(15, 0)},
+			outcome{DealerBlackjack, decimal.New(10, 0), dealerBlackjack.dealer, dealerBlackjack.player},
+		}},
+		{"DealerBlackjack/NotInsured", 1, nil, false, []event{
+			insuranceBet{decimal.New(5, 0)},
+			dealerPeek{true},
+			outcome{DealerBlackjack, decimal.New(10, 0), dealerBlackjack.dealer, dealerBlackjack.player},
+		}},
+		{"DealerBlackjack/OverInsured", 1, []int64{6, 0}, false, []event{
+			insuranceBet{decimal.New(5, 0)},
+			betRejected{BetError{InsuranceBet, BetAboveMaximum, decimal.New(6, 0), decimal.New(5, 0)}},
+			insuranceBet{decimal.New(5, 0)},
+			dealerPeek{true},
+			outcome{DealerBlackjack, decimal.New(10, 0), dealerBlackjack.dealer, dealerBlackjack.player},
+		}},
+		{"DealerBlackjack/OverInsuredRepeatedly", 1, []int64{6, 6, 6, 6, 0}, false, []event{
+			insuranceBet{decimal.New(5, 0)},
+			betRejected{BetError{InsuranceBet, BetAboveMaximum, decimal.New(6, 0), decimal.New(5, 0)}},
+			insuranceBet{decimal.New(5, 0)},
+			betRejected{BetError{InsuranceBet, BetAboveMaximum, decimal.New(6, 0), decimal.New(5, 0)}},
+			insuranceBet{decimal.New(5, 0)},
+			betRejected{BetError{InsuranceBet, BetAboveMaximum, decimal.New(6, 0), decimal.New(5, 0)}},
+			insuranceBet{decimal.New(5, 0)},
+			betRejected{BetError{InsuranceBet, BetAboveMaximum, decimal.New(6, 0), decimal.New(5, 0)}},
+			insuranceBet{decimal.New(5, 0)},
+			dealerPeek{true},
+			outcome{DealerBlackjack, decimal.New(10, 0), dealerBlackjack.dealer, dealerBlackjack.player},
+		}},
+		{"PlayerBlackjack/EvenMoney", 1302, nil, true, []event{
+			evenMoney{},
+			outcome{EvenMoney, decimal.New(20, 0), playerBlackjack.dealer, playerBlackjack.player},
+		}},
+		{"PlayerBlackjack/NoEvenMoney", 1302, nil, false, []event{
+			evenMoney{},
+			dealerPeek{false},
+			outcome{Blackjack, decimal.New(25, 0), playerBlackjack.dealer, playerBlackjack.player},
+		}},
+		{"NoBlackjack/Insured", 44, []int64{5}, false, []event{
+			insuranceBet{decimal.New(5, 0)},
+			dealerPeek{false},
+			insurance{false, decimal.New(-5, 0)},
+			hand{noBlackjack.dealer[:1], noBlackjack.player},
+			nextAction{[]Action{Hit, Stand, Double}, Stand},
+			dealerReveal{noBlackjack.dealer},
+			outcome{Lost, decimal.New(-10, 0), noBlackjack.dealer, noBlackjack.player},
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ui := &testUI{want: c.want, bet: 10, inss: c.ins, even: c.even}
+			testPlayUI(t, c.seed, rules, ui)
+		})
+	}
+}
+
+func TestNoHoleCardInsurance(t *testing.T) {
+	rules := testRules{insurance: true}
+	dealer := Hand{card.Heart(card.Ace), card.Heart(card.Ten)}
+	player := Hand{card.Heart(card.Jack), card.Heart(card.Queen)}
+
+	ui := &testUI{bet: 10, ins: 5, want: []event{
+		insuranceBet{decimal.New(5, 0)},
+		hand{dealer[:1], player},
+		nextAction{[]Action{Hit, Stand, Double}, Stand},
+		dealerCard{dealer[1], dealer},
+		insurance{true, decimal.New(15, 0)},
+		outcome{DealerBlackjack, decimal.New(10, 0), dealer, player},
+	}}
+	testPlayUI(t, 1, rules, ui)
+}
+
+func TestApplyInsuranceAboveMaximum(t *testing.T) {
+	var tbl *Table
+	seededShuffler(1, func() {
+		tbl = NewTable(testRules{holeCard: true, insurance: true, peek: PeekAceTen})
+	})
+	if err := tbl.Sit(0, player.NewFortune(decimal.New(50, 0))); err != nil {
+		t.Fatal(err)
+	}
+	mustApply(t, tbl, Move{Amount: decimal.New(10, 0)})
+
+	_, err := tbl.Apply(Move{Amount: decimal.New(6, 0)})
+	me, ok := err.(*MoveError)
+	if !ok {
+		t.Fatalf("got error %v, want: *MoveError", err)
+	}
+	be, ok := me.Err.(*BetError)
+	if !ok || be.Kind != InsuranceBet || be.Problem != BetAboveMaximum || !be.Limit.Equal(decimal.New(5, 0)) {
+		t.Fatalf("got error %v, want: insurance above the maximum of 5", me.Err)
+	}
+
+	// The table waits for another insurance bet.
+	if s := tbl.State(); s.Phase != PhaseInsuranceBet {
+		t.Fatalf("got phase %v, want: %v", s.Phase, PhaseInsuranceBet)
+	}
+	mustApply(t, tbl, Move{Amount: decimal.New(5, 0)})
+	if s := tbl.State(); !s.Fortune.Active().Equal(decimal.New(50, 0)) {
+		t.Errorf("got fortune %v, want: 50 after a won insurance", s.Fortune.Active())
+	}
+}
+
+func TestInsuranceMaximumZero(t *testing.T) {
+	rules := testRules{holeCard: true, insurance: true, peek: PeekAceTen, limits: Limits{Rounding: RoundDown}}
+	var tbl *Table
+	seededShuffler(1, func() {
+		tbl = NewTable(rules)
+	})
+	if err := tbl.Sit(0, player.NewFortune(decimal.New(50, 0))); err != nil {
+		t.Fatal(err)
+	}
+	mustApply(t, tbl, Move{Amount: decimal.New(1, 0)})
+
+	// Half a bet of 1 rounds down to no insurance at all.
+	if s := tbl.State(); s.Phase == PhaseInsuranceBet {
+		t.Fatalf("got phase %v, want: no insurance offered", s.Phase)
+	}
+
+	g := &game{
+		table:   &table{rules: rules},
+		fortune: player.NewFortune(decimal.New(50, 0)),
+		bets:    []*bet{{amount: decimal.New(1, 0)}},
+	}
+	be := g.checkBet(InsuranceBet, decimal.New(40, 0))
+	if be == nil || be.Problem != BetAboveMaximum || !be.Limit.IsZero() {
+		t.Errorf("got error %v, want: insurance above the maximum of 0", be)
+	}
+}
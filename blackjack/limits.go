@@ -0,0 +1,149 @@
+package blackjack
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Limits are the betting limits of a table. A zero value means there is
+// no limit.
+type Limits struct {
+	Min  decimal.Decimal `json:"min" toml:"min" yaml:"min"`
+	Max  decimal.Decimal `json:"max" toml:"max" yaml:"max"`
+	Unit decimal.Decimal `json:"unit" toml:"unit" yaml:"unit"` // smallest chip
+
+	SideMin decimal.Decimal `json:"sideMin" toml:"sideMin" yaml:"sideMin"`
+	SideMax decimal.Decimal `json:"sideMax" toml:"sideMax" yaml:"sideMax"`
+
+	// MaxPayout caps the winnings paid on a single bet.
+	MaxPayout decimal.Decimal `json:"maxPayout" toml:"maxPayout" yaml:"maxPayout"`
+
+	// Rounding rounds fractional payouts to the chip unit, or to whole
+	// amounts without a unit.
+	Rounding Rounding `json:"rounding" toml:"rounding" yaml:"rounding"`
+}
+
+// Rounding represents a policy to round fractional payouts.
+type Rounding int
+
+// Rounding policies, a payout of 7.5 is paid as 7.5, 7, 8 and 7.
+const (
+	RoundExact Rounding = iota
+	RoundDown
+	RoundHalfUp
+	RoundHouse // to nearest, halves in favour of the house
+)
+
+//go:generate stringer -type=Rounding
+
+// round rounds payout d to a multiple of unit, or 1 if unit is zero.
+func (r Rounding) round(d, unit decimal.Decimal) decimal.Decimal {
+	if r == RoundExact {
+		return d
+	}
+	if unit.Sign() <= 0 {
+		unit = decimal.New(1, 0)
+	}
+
+	half := decimal.New(5, -1)
+	q := d.Div(unit)
+	switch r {
+	case RoundDown:
+		q = q.Floor()
+	case RoundHalfUp:
+		q = q.Add(half).Floor()
+	case RoundHouse:
+		q = q.Sub(half).Ceil()
+	}
+	return q.Mul(unit)
+}
+
+// BetKind represents the kind of a bet.
+type BetKind int
+
+// Kinds of bets.
+const (
+	MainBet BetKind = iota
+	DoubleBet
+	SplitBet
+	SideBet
+	InsuranceBet
+)
+
+//go:generate stringer -type=BetKind
+
+// BetProblem represents the reason a bet is rejected.
+type BetProblem int
+
+// Reasons to reject a bet.
+const (
+	BetBelowMinimum BetProblem = iota
+	BetAboveMaximum
+	BetNotInUnits
+	BetInsufficientFunds
+)
+
+//go:generate stringer -type=BetProblem
+
+// BetError describes why a bet is rejected. Limit is the table limit,
+// chip unit or available fortune that is violated.
+type BetError struct {
+	Kind    BetKind
+	Problem BetProblem
+	Amount  decimal.Decimal
+	Limit   decimal.Decimal
+}
+
+func (e *BetError) Error() string {
+	kind := map[BetKind]string{
+		MainBet:      "bet",
+		DoubleBet:    "double",
+		SplitBet:     "split",
+		SideBet:      "side bet",
+		InsuranceBet: "insurance",
+	}[e.Kind]
+
+	switch e.Problem {
+	case BetBelowMinimum:
+		return fmt.Sprintf("blackjack: %s of %v is below the minimum of %v", kind, e.Amount, e.Limit)
+	case BetAboveMaximum:
+		return fmt.Sprintf("blackjack: %s of %v is above the maximum of %v", kind, e.Amount, e.Limit)
+	case BetNotInUnits:
+		return fmt.Sprintf("blackjack: %s of %v is not a multiple of %v", kind, e.Amount, e.Limit)
+	default:
+		return fmt.Sprintf("blackjack: %s of %v is more than the available %v", kind, e.Amount, e.Limit)
+	}
+}
+
+// checkBet validates an amount of a kind of bet against the table limits
+// and the fortune of the player. It returns nil if the bet is valid.
+func (g *game) checkBet(kind BetKind, amount decimal.Decimal) *BetError {
+	l := g.rules.Limits()
+	min, max, unit := l.Min, l.Max, l.Unit
+	limited := max.Sign() > 0 // a zero maximum is no limit
+
+	switch kind {
+	case SideBet:
+		min, max = l.SideMin, l.SideMax
+		limited = max.Sign() > 0
+	case InsuranceBet:
+		min, max, unit = decimal.Zero, g.maxInsurance(), decimal.Zero
+		limited = true
+	}
+
+	err := &BetError{Kind: kind, Amount: amount}
+	switch {
+	case amount.Cmp(min) < 0:
+		err.Problem, err.Limit = BetBelowMinimum, min
+	case limited && amount.Cmp(max) > 0:
+		err.Problem, err.Limit = BetAboveMaximum, max
+	case unit.Sign() > 0 && !amount.Mod(unit).IsZero():
+		err.Problem, err.Limit = BetNotInUnits, unit
+	case !g.fortune.Has(amount):
+		err.Problem, err.Limit = BetInsufficientFunds, g.fortune.Active()
+	default:
+		return nil
+	}
+	return err
+}
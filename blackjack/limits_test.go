@@ -0,0 +1,282 @@
+package blackjack
+
+import (
+	"testing"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+var testLimits = Limits{
+	Min:     decimal.New(10, 0),
+	Max:     decimal.New(40, 0),
+	Unit:    decimal.New(5, 0),
+	SideMin: decimal.New(1, 0),
+	SideMax: decimal.New(5, 0),
+}
+
+func TestCheckBet(t *testing.T) {
+	cases := []struct {
+		kind    BetKind
+		amount  int64
+		ok      bool
+		problem BetProblem
+		limit   int64
+	}{
+		{MainBet, 10, true, 0, 0},
+		{MainBet, 30, true, 0, 0},
+		{MainBet, 5, false, BetBelowMinimum, 10},
+		{MainBet, 45, false, BetAboveMaximum, 40},
+		{MainBet, 12, false, BetNotInUnits, 5},
+		{DoubleBet, 35, false, BetInsufficientFunds, 30},
+		{SplitBet, 30, true, 0, 0},
+		{SideBet, 5, true, 0, 0},
+		{SideBet, 10, false, BetAboveMaximum, 5},
+		{InsuranceBet, 3, true, 0, 0},
+		{InsuranceBet, 6, false, BetAboveMaximum, 5},
+	}
+
+	for _, c := range cases {
+		g := &game{
+			table:   &table{rules: testRules{limits: testLimits}},
+			fortune: player.NewFortune(decimal.New(30, 0)),
+			bets:    []*bet{{amount: decimal.New(10, 0)}},
+		}
+
+		amount := decimal.New(c.amount, 0)
+		err := g.checkBet(c.kind, amount)
+		if c.ok {
+			if err != nil {
+				t.Errorf("%v %v: %v", c.kind, c.amount, err)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Errorf("%v %v: no error", c.kind, c.amount)
+			continue
+		}
+		if err.Kind != c.kind || err.Problem != c.problem {
+			t.Errorf("%v %v: got %v %v, want: %v", c.kind, c.amount, err.Kind, err.Problem, c.problem)
+		}
+		if !err.Amount.Equal(amount) || !err.Limit.Equal(decimal.New(c.limit, 0)) {
+			t.Errorf("%v %v: got amount %v limit %v, want limit: %v",
+				c.kind, c.amount, err.Amount, err.Limit, c.limit)
+		}
+	}
+}
+
+func TestBetRejected(t *testing.T) {
+	rules := testRules{limits: testLimits}
+	reject := func(p BetProblem, amount, limit int64) event {
+		return betRejected{BetError{MainBet, p, decimal.New(amount, 0), decimal.New(limit, 0)}}
+	}
+
+	ui := &testUI{bets: []int64{5, 45, 12}, bet: 10, want: []event{
+		reject(BetBelowMinimum, 5, 10),
+		reject(BetAboveMaximum, 45, 40),
+		reject(BetNotInUnits, 12, 5),
+		hand{
+			dealer: Hand{card.Spade(card.Eight)},
+			player: Hand{card.Diamond(card.Eight), card.Diamond(card.Ten)},
+		},
+		nextAction{[]Action{Hit, Stand, Double}, Stand},
+		dealerCard{
+			card: card.Club(card.Ace),
+			hand: Hand{card.Spade(card.Eight), card.Club(card.Ace)},
+		},
+		outcome{
+			outcome: Lost,
+			amount:  decimal.New(-10, 0),
+			dealer:  Hand{card.Spade(card.Eight), card.Club(card.Ace)},
+			player:  Hand{card.Diamond(card.Eight), card.Diamond(card.Ten)},
+		},
+	}}
+	testPlayUI(t, 6, rules, ui)
+}
+
+func TestDoubleLimits(t *testing.T) {
+	rules := testRules{limits: testLimits}
+	testPlay(t, 6, rules, 30, 0, []event{
+		hand{
+			dealer: Hand{card.Spade(card.Eight)},
+			player: Hand{card.Diamond(card.Eight), card.Diamond(card.Ten)},
+		},
+		nextAction{[]Action{Hit, Stand}, Stand},
+		dealerCard{
+			card: card.Club(card.Ace),
+			hand: Hand{card.Spade(card.Eight), card.Club(card.Ace)},
+		},
+		outcome{
+			outcome: Lost,
+			amount:  decimal.New(-30, 0),
+			dealer:  Hand{card.Spade(card.Eight), card.Club(card.Ace)},
+			player:  Hand{card.Diamond(card.Eight), card.Diamond(card.Ten)},
+		},
+	})
+}
+
+func TestApplyDoubleLimits(t *testing.T) {
+	var tbl *Table
+	seededShuffler(6, func() {
+		tbl = NewTable(testRules{limits: testLimits})
+	})
+	if err := tbl.Sit(0, player.NewFortune(decimal.New(50, 0))); err != nil {
+		t.Fatal(err)
+	}
+	mustApply(t, tbl, Move{Amount: decimal.New(30, 0)})
+
+	_, err := tbl.Apply(Move{Action: Double})
+	me, ok := err.(*MoveError)
+	if !ok {
+		t.Fatalf("got error %v, want: *MoveError", err)
+	}
+	be, ok := me.Err.(*BetError)
+	if !ok {
+		t.Fatalf("got error %v, want: *BetError", me.Err)
+	}
+	want := BetError{DoubleBet, BetInsufficientFunds, decimal.New(30, 0), decimal.New(20, 0)}
+	if be.Kind != want.Kind || be.Problem != want.Problem ||
+		!be.Amount.Equal(want.Amount) || !be.Limit.Equal(want.Limit) {
+		t.Errorf("got %+v, want: %+v", *be, want)
+	}
+
+	// The double isn't a legal action and the table waits for a move.
+	if got := tbl.LegalActions(); validAction(Double, got...) {
+		t.Errorf("got actions %v, want: no Double", got)
+	}
+	mustApply(t, tbl, Move{Action: Stand})
+	if s := tbl.State(); s.Phase != PhaseNewGame {
+		t.Errorf("got phase %v, want: %v", s.Phase, PhaseNewGame)
+	}
+}
+
+func TestSideBetRejected(t *testing.T) {
+	rules := NewRuleSet("Limited", HollandCasino)
+	rules.TableLimits = testLimits
+
+	testPlay(t, 0, rules, 10, 10, []event{
+		betRejected{BetError{SideBet, BetAboveMaximum, decimal.New(10, 0), decimal.New(5, 0)}},
+		dealerCard{
+			card: card.Heart(card.King),
+			hand: Hand{card.Spade(card.King), card.Heart(card.King)},
+		},
+		outcome{
+			outcome: Blackjack,
+			amount:  decimal.New(25, 0),
+			dealer:  Hand{card.Spade(card.King), card.Heart(card.King)},
+			player:  Hand{card.Club(card.Ace), card.Heart(card.Queen)},
+		},
+	})
+}
+
+func TestMaxPayout(t *testing.T) {
+	rules := NewRuleSet("Capped", HollandCasino)
+	rules.TableLimits.MaxPayout = decimal.New(12, 0)
+
+	testPlay(t, 0, rules, 10, 0, []event{
+		dealerCard{
+			card: card.Heart(card.King),
+			hand: Hand{card.Spade(card.King), card.Heart(card.King)},
+		},
+		outcome{
+			outcome: Blackjack,
+			amount:  decimal.New(22, 0),
+			dealer:  Hand{card.Spade(card.King), card.Heart(card.King)},
+			player:  Hand{card.Club(card.Ace), card.Heart(card.Queen)},
+		},
+	})
+}
+
+func TestRounding(t *testing.T) {
+	cases := []struct {
+		in    decimal.Decimal
+		unit  int64
+		exact decimal.Decimal
+		down  int64
+		up    int64
+		house int64
+	}{
+		{decimal.New(75, -1), 0, decimal.New(75, -1), 7, 8, 7},
+		{decimal.New(76, -1), 0, decimal.New(76, -1), 7, 8, 8},
+		{decimal.New(74, -1), 0, decimal.New(74, -1), 7, 7, 7},
+		{decimal.New(7, 0), 0, decimal.New(7, 0), 7, 7, 7},
+		{decimal.New(25, -1), 1, decimal.New(25, -1), 2, 3, 2},
+		{decimal.New(125, -1), 5, decimal.New(125, -1), 10, 15, 10},
+		{decimal.New(12, 0), 5, decimal.New(12, 0), 10, 10, 10},
+		{decimal.New(13, 0), 5, decimal.New(13, 0), 10, 15, 15},
+	}
+
+	for _, c := range cases {
+		unit := decimal.New(c.unit, 0)
+		want := map[Rounding]decimal.Decimal{
+			RoundExact:  c.exact,
+			RoundDown:   decimal.New(c.down, 0),
+			RoundHalfUp: decimal.New(c.up, 0),
+			RoundHouse:  decimal.New(c.house, 0),
+		}
+		for r, want := range want {
+			if got := r.round(c.in, unit); !got.Equal(want) {
+				t.Errorf("%v %v unit %v: got: %v, want: %v", r, c.in, c.unit, got, want)
+			}
+		}
+	}
+}
+
+func TestSettleRounding(t *testing.T) {
+	bj := Hand{card.Spade(card.Ace), card.Heart(card.King)}
+	twenty := Hand{card.Club(card.Queen), card.Diamond(card.King)}
+	five := decimal.New(5, 0)
+
+	// Payouts of a bet of 5 per rounding policy: exact, down, half up
+	// and house.
+	cases := []struct {
+		name    string
+		outcome Outcome
+		hand    Hand
+		want    [4]decimal.Decimal
+	}{
+		{"Blackjack", Blackjack, bj, [4]decimal.Decimal{
+			decimal.New(125, -1), decimal.New(12, 0), decimal.New(13, 0), decimal.New(12, 0),
+		}},
+		{"Surrendered", Surrendered, twenty, [4]decimal.Decimal{
+			decimal.New(25, -1), decimal.New(2, 0), decimal.New(3, 0), decimal.New(2, 0),
+		}},
+		{"Won", Won, twenty, [4]decimal.Decimal{
+			decimal.New(10, 0), decimal.New(10, 0), decimal.New(10, 0), decimal.New(10, 0),
+		}},
+	}
+
+	for _, c := range cases {
+		for r, want := range c.want {
+			r := Rounding(r)
+			f := player.NewFortune(decimal.Zero)
+			g := &game{
+				ui:      settleUI{},
+				table:   &table{rules: testRules{limits: Limits{Rounding: r}}},
+				fortune: f,
+			}
+			g.settle(c.outcome, five, &bet{hand: c.hand})
+
+			if !f.Active().Equal(want) {
+				t.Errorf("%s %v: got payout %v, want: %v", c.name, r, f.Active(), want)
+			}
+		}
+	}
+}
+
+func TestInsuranceRounding(t *testing.T) {
+	for r, want := range []decimal.Decimal{
+		decimal.New(25, -1), decimal.New(2, 0), decimal.New(2, 0), decimal.New(2, 0),
+	} {
+		g := &game{
+			table: &table{rules: testRules{limits: Limits{Rounding: Rounding(r)}}},
+			bets:  []*bet{{amount: decimal.New(5, 0)}},
+		}
+		if got := g.maxInsurance(); !got.Equal(want) {
+			t.Errorf("%v: got: %v, want: %v", Rounding(r), got, want)
+		}
+	}
+}
@@ -0,0 +1,444 @@
+package blackjack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Rule notation tokens, they are matched case insensitive.
+//
+//	6D         number of decks
+//	H17, S17   dealer hits or stands on soft 17
+//	ES, LS     early or late surrender, against any up-card
+//	ES10, LS10 surrender against 2 to 10, not against an ace
+//	ES2-9      surrender against 2 to 9, not against an ace or ten
+//	SP4        split up to 4 hands, unlimited when omitted
+//	SUT        split unequal tens, like K-Q
+//	RSA        resplit aces
+//	HSA        hit split aces
+//	DSA        double split aces
+//	DOA        double on any two cards
+//	D9, D10    double on 9-11 or 10-11 only
+//	DAS        double after split
+//	BJS        a two card 21 after splitting is a blackjack
+//	ENHC       no hole card
+//	PEEK       dealer peeks for blackjack on an ace or ten
+//	PEEKA      dealer peeks for blackjack on an ace only
+//	NOPEEK     dealer doesn't peek for blackjack
+//	OBO        only the original bet is lost to a dealer blackjack
+//	3:2        blackjack pays 3 to 2
+//	DWT        dealer wins ties
+//	5CC        five card Charlie wins 1:1, also 6CC and 7CC
+//	PP6/12/25  perfect pair pays 6, 12 and 25 for a mixed, same or perfect pair
+//	INS        insurance
+//	EM         even money
+//
+// Payouts, bonuses and limits other than the defaults are set by a key and
+// a value, like WIN=1:1 or MIN=10. Ratios are written like 3:2, amounts
+// as decimals.
+//
+//	WIN, SURRENDER, INSURANCE  win, surrender and insurance payout ratio
+//	SUITEDBJ                   suited blackjack payout ratio
+//	HAND5/21S                  payout ratio of a winning hand of at least 5
+//	                           cards, a total of 21 (0 for any) and suited
+//	                           (S, optional), once for every hand payout
+//	CHARLIE                    Charlie payout ratio
+//	678, 777, 5C21             suited 6-7-8, 7-7-7 and five card 21 ratio
+//	MIN, MAX, UNIT             table limits and the smallest chip
+//	SIDEMIN, SIDEMAX           side bet limits
+//	MAXPAYOUT                  maximum payout on a bet
+//	ROUND                      rounding of payouts: DOWN, HALFUP or HOUSE
+//
+// Omitted options are off, except that a game with a hole card peeks on
+// an ace or ten and doubling is on any two cards.
+
+// ParseRules parses a rule set in the conventional notation, like
+// "6D H17 DAS RSA LS 3:2 ENHC". The returned rule set is validated.
+func ParseRules(s string) (*RuleSet, error) {
+	rs := &RuleSet{}
+	seen := make(map[string]string)
+	peek := ""
+
+	set := func(group, tok string) error {
+		if prev, ok := seen[group]; ok {
+			return fmt.Errorf("blackjack: rule %q conflicts with %q", tok, prev)
+		}
+		seen[group] = tok
+		return nil
+	}
+
+	for _, tok := range strings.Fields(s) {
+		var err error
+		switch t := strings.ToUpper(tok); {
+		case strings.Contains(t, "="):
+			i := strings.IndexByte(t, '=')
+			key := t[:i]
+			if !strings.HasPrefix(key, "HAND") {
+				err = set(key, tok)
+			}
+			if err == nil && parseSetting(rs, key, t[i+1:]) != nil {
+				err = fmt.Errorf("blackjack: invalid rule %q", tok)
+			}
+		case t == "H17", t == "S17":
+			err = set("soft17", tok)
+			rs.HitSoft17 = t == "H17"
+		case t == "ES", t == "ES10", t == "ES2-9", t == "LS", t == "LS10", t == "LS2-9":
+			err = set("surrender", tok)
+			rs.SurrenderRule = EarlySurrender
+			if t[0] == 'L' {
+				rs.SurrenderRule = LateSurrender
+			}
+			switch t[2:] {
+			case "10":
+				rs.SurrenderUp = SurrenderNotAce
+			case "2-9":
+				rs.SurrenderUp = SurrenderNotAceTen
+			}
+		case t == "SUT":
+			err = set(t, tok)
+			rs.UnequalTens = true
+		case t == "RSA":
+			err = set(t, tok)
+			rs.RSA = true
+		case t == "HSA":
+			err = set(t, tok)
+			rs.HSA = true
+		case t == "DSA":
+			err = set(t, tok)
+			rs.DSA = true
+		case t == "DOA", t == "D9", t == "D10":
+			err = set("double", tok)
+			rs.DoubleRule = map[string]DoubleRule{
+				"DOA": DoubleAny,
+				"D9":  DoubleOnly9_10_11,
+				"D10": DoubleOnly10_11,
+			}[t]
+		case t == "DAS":
+			err = set(t, tok)
+			rs.DAS = true
+		case t == "BJS":
+			err = set(t, tok)
+			rs.BJAfterSplit = true
+		case t == "ENHC", t == "PEEK", t == "PEEKA", t == "NOPEEK":
+			err = set("peek", tok)
+			peek = t
+		case t == "OBO":
+			err = set(t, tok)
+			rs.OBO = true
+		case t == "DWT":
+			err = set(t, tok)
+			rs.TiesLose = true
+		case t == "INS":
+			err = set(t, tok)
+			rs.InsuranceBets = true
+		case t == "EM":
+			err = set(t, tok)
+			rs.EvenMoneyBets = true
+		case strings.HasSuffix(t, "CC"):
+			err = set("charlie", tok)
+			if err == nil {
+				rs.BonusHands.Charlie, err = strconv.Atoi(t[:len(t)-2])
+			}
+		case strings.HasSuffix(t, "D"):
+			err = set("decks", tok)
+			if err == nil {
+				var n uint64
+				n, err = strconv.ParseUint(t[:len(t)-1], 10, 0)
+				rs.Decks = uint(n)
+			}
+		case strings.HasPrefix(t, "SP"):
+			err = set("split", tok)
+			if err == nil {
+				rs.MaxHands, err = strconv.Atoi(t[2:])
+			}
+		case strings.HasPrefix(t, "PP"):
+			err = set("pp", tok)
+			if err == nil {
+				rs.PerfectPairs = true
+				rs.PerfectPairPays, err = parsePerfectPair(t[2:])
+			}
+		case strings.Contains(t, ":"):
+			err = set("ratio", tok)
+			if err == nil {
+				rs.BJRatio, err = parseRatio(t)
+			}
+		default:
+			err = fmt.Errorf("blackjack: unknown rule %q", tok)
+		}
+
+		if err != nil {
+			if _, ok := err.(*strconv.NumError); ok {
+				err = fmt.Errorf("blackjack: invalid rule %q", tok)
+			}
+			return nil, err
+		}
+	}
+
+	switch peek {
+	case "ENHC":
+		rs.ENHC = true
+	case "", "PEEK":
+		rs.PeekRule = PeekAceTen
+	case "PEEKA":
+		rs.PeekRule = PeekAce
+	}
+
+	rs.Name = FormatRules(rs)
+	if err := rs.Validate(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// parseSetting sets the payout, bonus or limit of key to value.
+func parseSetting(rs *RuleSet, key, value string) error {
+	ratio := func(d *decimal.Decimal) (err error) {
+		*d, err = parseRatio(value)
+		return err
+	}
+	amount := func(d *decimal.Decimal) (err error) {
+		*d, err = decimal.NewFromString(value)
+		return err
+	}
+
+	p, b, l := &rs.PayoutTable, &rs.BonusHands, &rs.TableLimits
+	switch key {
+	case "WIN":
+		return ratio(&p.Win)
+	case "SURRENDER":
+		return ratio(&p.Surrender)
+	case "INSURANCE":
+		return ratio(&p.Insurance)
+	case "SUITEDBJ":
+		return ratio(&p.SuitedBlackjack)
+	case "CHARLIE":
+		return ratio(&b.CharlieRatio)
+	case "678":
+		return ratio(&b.Suited678)
+	case "777":
+		return ratio(&b.Triple7)
+	case "5C21":
+		return ratio(&b.FiveCard21)
+	case "MIN":
+		return amount(&l.Min)
+	case "MAX":
+		return amount(&l.Max)
+	case "UNIT":
+		return amount(&l.Unit)
+	case "SIDEMIN":
+		return amount(&l.SideMin)
+	case "SIDEMAX":
+		return amount(&l.SideMax)
+	case "MAXPAYOUT":
+		return amount(&l.MaxPayout)
+	case "ROUND":
+		for r := RoundExact; int(r) < len(_Rounding_index)-1; r++ {
+			if value == roundingName(r) {
+				l.Rounding = r
+				return nil
+			}
+		}
+		return fmt.Errorf("blackjack: unknown rounding %q", value)
+	}
+
+	if !strings.HasPrefix(key, "HAND") {
+		return fmt.Errorf("blackjack: unknown rule %q", key)
+	}
+	spec := strings.TrimPrefix(key, "HAND")
+	hp := HandPayout{Suited: strings.HasSuffix(spec, "S")}
+	f := strings.Split(strings.TrimSuffix(spec, "S"), "/")
+	if len(f) != 2 {
+		return fmt.Errorf("blackjack: invalid hand payout %q", key)
+	}
+	var err error
+	if hp.Cards, err = strconv.Atoi(f[0]); err != nil {
+		return err
+	}
+	if hp.Total, err = strconv.Atoi(f[1]); err != nil {
+		return err
+	}
+	if err := ratio(&hp.Ratio); err != nil {
+		return err
+	}
+	p.Hands = append(p.Hands, hp)
+	return nil
+}
+
+// roundingName returns the name of rounding r in the notation.
+func roundingName(r Rounding) string {
+	return strings.ToUpper(strings.TrimPrefix(r.String(), "Round"))
+}
+
+func parseRatio(s string) (decimal.Decimal, error) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return decimal.Zero, fmt.Errorf("blackjack: invalid ratio %q", s)
+	}
+	a, err := strconv.ParseInt(s[:i], 10, 64)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	b, err := strconv.ParseInt(s[i+1:], 10, 64)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if b == 0 {
+		return decimal.Zero, fmt.Errorf("blackjack: invalid ratio %q", s)
+	}
+	return ratio(decimal.New(a, 0), decimal.New(b, 0)), nil
+}
+
+// ratio returns a divided by b, rounded to 4 decimal places if it has
+// more.
+func ratio(a, b decimal.Decimal) decimal.Decimal {
+	if q := a.Div(b); q.Mul(b).Equal(a) {
+		return q
+	}
+	return a.DivRound(b, 4)
+}
+
+func parsePerfectPair(s string) (p [3]int, err error) {
+	f := strings.Split(s, "/")
+	if len(f) != len(p) {
+		return p, fmt.Errorf("blackjack: invalid perfect pair ratio %q", s)
+	}
+	for i := range p {
+		if p[i], err = strconv.Atoi(f[i]); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// FormatRules formats the rules r in the conventional notation, ParseRules
+// parses it as the same rules.
+func FormatRules(r Rules) string {
+	var f []string
+	add := func(tok string, ok bool) {
+		if ok {
+			f = append(f, tok)
+		}
+	}
+
+	f = append(f, fmt.Sprintf("%dD", r.NumDecks()))
+	add("H17", r.DealerHitSoft17())
+	add("S17", !r.DealerHitSoft17())
+
+	if s := r.Surrender(); s != NoSurrender {
+		tok := "ES"
+		if s == LateSurrender {
+			tok = "LS"
+		}
+		switch r.SurrenderUpCard() {
+		case SurrenderNotAce:
+			tok += "10"
+		case SurrenderNotAceTen:
+			tok += "2-9"
+		}
+		f = append(f, tok)
+	}
+
+	add(fmt.Sprintf("SP%d", r.SplitHands()), r.SplitHands() > 0)
+	add("SUT", r.SplitUnequalTens())
+	add("RSA", r.ResplitAces())
+	add("HSA", r.HitSplitAces())
+	add("DSA", r.DoubleSplitAces())
+
+	switch r.Double() {
+	case DoubleAny:
+		f = append(f, "DOA")
+	case DoubleOnly9_10_11:
+		f = append(f, "D9")
+	case DoubleOnly10_11:
+		f = append(f, "D10")
+	}
+
+	add("DAS", r.DoubleAfterSplit())
+	add("BJS", r.BlackjackAfterSplit())
+
+	if r.NoHoleCard() {
+		f = append(f, "ENHC")
+	} else {
+		switch r.Peek() {
+		case NoPeek:
+			f = append(f, "NOPEEK")
+		case PeekAce:
+			f = append(f, "PEEKA")
+		case PeekAceTen:
+			f = append(f, "PEEK")
+		}
+	}
+
+	add("OBO", r.OriginalBetsOnly())
+	f = append(f, formatRatio(r.BlackjackRatio()))
+	add("DWT", r.DealerWinsTie())
+	add(fmt.Sprintf("%dCC", r.Bonuses().Charlie), r.Bonuses().Charlie > 0)
+
+	if r.PerfectPair() {
+		m, s, p := r.PerfectPairRatio()
+		f = append(f, fmt.Sprintf("PP%d/%d/%d", m, s, p))
+	}
+
+	add("INS", r.Insurance())
+	add("EM", r.EvenMoney())
+
+	addRatio := func(key string, d decimal.Decimal) {
+		add(key+"="+formatRatio(d), !d.IsZero())
+	}
+	p := r.Payouts()
+	addRatio("WIN", p.Win)
+	addRatio("SURRENDER", p.Surrender)
+	addRatio("INSURANCE", p.Insurance)
+	addRatio("SUITEDBJ", p.SuitedBlackjack)
+	for _, hp := range p.Hands {
+		tok := fmt.Sprintf("HAND%d/%d", hp.Cards, hp.Total)
+		if hp.Suited {
+			tok += "S"
+		}
+		f = append(f, tok+"="+formatRatio(hp.Ratio))
+	}
+
+	b := r.Bonuses()
+	addRatio("CHARLIE", b.CharlieRatio)
+	addRatio("678", b.Suited678)
+	addRatio("777", b.Triple7)
+	addRatio("5C21", b.FiveCard21)
+
+	addAmount := func(key string, d decimal.Decimal) {
+		add(key+"="+d.String(), !d.IsZero())
+	}
+	l := r.Limits()
+	addAmount("MIN", l.Min)
+	addAmount("MAX", l.Max)
+	addAmount("UNIT", l.Unit)
+	addAmount("SIDEMIN", l.SideMin)
+	addAmount("SIDEMAX", l.SideMax)
+	addAmount("MAXPAYOUT", l.MaxPayout)
+	add("ROUND="+roundingName(l.Rounding), l.Rounding != RoundExact)
+	return strings.Join(f, " ")
+}
+
+// formatRatio formats ratio d as a fraction with the smallest denominator
+// that parses as d. A ratio of 4 decimal places can be a rounded
+// fraction, like 1.3333 is 4:3.
+func formatRatio(d decimal.Decimal) string {
+	rounded := !d.Equal(d.Round(3))
+	for _, exact := range []bool{true, false} {
+		for b := int64(1); b <= 100 && (exact || rounded); b++ {
+			db := decimal.New(b, 0)
+			a := d.Mul(db).Round(0)
+			if q := ratio(a, db); q.Equal(d) && (!exact || q.Mul(db).Equal(a)) {
+				return fmt.Sprintf("%s:%d", a, b)
+			}
+		}
+	}
+	// A decimal is a fraction with a power of ten as denominator.
+	places := 0
+	if s := d.String(); strings.Contains(s, ".") {
+		places = len(s) - strings.IndexByte(s, '.') - 1
+	}
+	return fmt.Sprintf("%s:%s", d.Shift(int32(places)), decimal.New(1, int32(places)))
+}
@@ -0,0 +1,176 @@
+package blackjack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFormatRules(t *testing.T) {
+	cases := []struct {
+		rules Rules
+		want  string
+	}{
+		{HollandCasino, "6D H17 D9 DAS BJS ENHC 3:2 PP6/12/25"},
+		{TapTapBoom, "6D H17 SP2 DOA DAS BJS PEEK 3:2 INS EM"},
+		{testRuleSet, "8D H17 LS10 SP4 RSA D10 DAS PEEK 6:5 INS EM ROUND=HALFUP"},
+	}
+
+	for _, c := range cases {
+		got := FormatRules(c.rules)
+		if got != c.want {
+			t.Errorf("%v: got: %q, want: %q", c.rules, got, c.want)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	cases := []string{
+		FormatRules(HollandCasino),
+		FormatRules(TapTapBoom),
+		FormatRules(testRuleSet),
+		"1D S17 ES2-9 SUT RSA HSA DSA DOA DAS NOPEEK OBO 1:1 DWT",
+		"2D S17 ES SP3 D10 PEEKA 7:5 INS",
+		"6D H17 DOA ENHC 3:2 5CC",
+		"6D S17 DOA PEEK 3:2 6CC WIN=1:1 SURRENDER=2:5 HAND5/0=2:1 HAND3/21S=3:1 CHARLIE=2:1 777=3:1 MIN=10 UNIT=0.5 ROUND=HALFUP",
+	}
+
+	for _, s := range cases {
+		rs, err := ParseRules(s)
+		if err != nil {
+			t.Errorf("%q: %v", s, err)
+			continue
+		}
+		if got := FormatRules(rs); got != s {
+			t.Errorf("got: %q, want: %q", got, s)
+		}
+		if rs.Name != s {
+			t.Errorf("got name: %q, want: %q", rs.Name, s)
+		}
+	}
+}
+
+func TestFormatRulesRoundTrip(t *testing.T) {
+	custom := NewRuleSet("", LasVegasStrip)
+	custom.BJRatio = decimal.New(1234, -3)
+	custom.PayoutTable = Payouts{
+		SuitedBlackjack: decimal.New(2, 0),
+		Win:             decimal.New(1, 0),
+		Surrender:       decimal.New(4, -1),
+		Insurance:       decimal.New(3, 0),
+		Hands: []HandPayout{
+			{Cards: 5, Ratio: decimal.New(2, 0)},
+			{Cards: 3, Total: 21, Suited: true, Ratio: decimal.New(5, -1)},
+		},
+	}
+	custom.BonusHands = Bonuses{
+		Charlie:      6,
+		CharlieRatio: decimal.New(15, -1),
+		Suited678:    decimal.New(2, 0),
+		Triple7:      decimal.New(3, 0),
+		FiveCard21:   decimal.New(4, 0),
+	}
+	custom.TableLimits = Limits{
+		Min:       decimal.New(5, 0),
+		Max:       decimal.New(500, 0),
+		Unit:      decimal.New(5, -1),
+		SideMin:   decimal.New(1, 0),
+		SideMax:   decimal.New(25, 0),
+		MaxPayout: decimal.New(10000, 0),
+		Rounding:  RoundHouse,
+	}
+
+	for _, r := range append(AvailableRules, Rules(testRuleSet), custom) {
+		s := FormatRules(r)
+		rs, err := ParseRules(s)
+		if err != nil {
+			t.Errorf("%q: %v", s, err)
+			continue
+		}
+		got, want := NewRuleSet("", rs), NewRuleSet("", r)
+		if g, w := fmt.Sprintf("%+v", got), fmt.Sprintf("%+v", want); g != w {
+			t.Errorf("%q: got rules %s, want: %s", s, g, w)
+		}
+		if got := FormatRules(rs); got != s {
+			t.Errorf("got: %q, want: %q", got, s)
+		}
+	}
+}
+
+func TestFormatRatio(t *testing.T) {
+	cases := []struct {
+		ratio decimal.Decimal
+		want  string
+	}{
+		{decimal.New(15, -1), "3:2"},
+		{decimal.New(12, -1), "6:5"},
+		{decimal.New(1, 0), "1:1"},
+		{decimal.New(13333, -4), "4:3"},
+		{decimal.New(1234, -3), "1234:1000"},
+		{decimal.New(3125, -5), "1:32"},
+	}
+
+	for _, c := range cases {
+		got := formatRatio(c.ratio)
+		if got != c.want {
+			t.Errorf("%v: got: %q, want: %q", c.ratio, got, c.want)
+		}
+		if r, err := parseRatio(got); err != nil || !r.Equal(c.ratio) {
+			t.Errorf("%q: got ratio %v (%v), want: %v", got, r, err, c.ratio)
+		}
+	}
+}
+
+func TestParseRulesDefaults(t *testing.T) {
+	rs, err := ParseRules("6d s17 das ls 7:5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &RuleSet{
+		Decks:         6,
+		SurrenderRule: LateSurrender,
+		DoubleRule:    DoubleAny,
+		DAS:           true,
+		PeekRule:      PeekAceTen,
+		BJRatio:       decimal.New(14, -1),
+	}
+	want.Name = FormatRules(want)
+	if got := FormatRules(rs); got != want.Name {
+		t.Errorf("got: %q, want: %q", got, want.Name)
+	}
+	if !rs.BJRatio.Equal(want.BJRatio) {
+		t.Errorf("got ratio: %v, want: %v", rs.BJRatio, want.BJRatio)
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	cases := []string{
+		"",
+		"6D",
+		"6D H17 S17 3:2",
+		"6D H17 3:2 6:5",
+		"6D H17 3:0",
+		"6D H17 3:2 ENHC PEEK",
+		"6D H17 3:2 SURRENDER",
+		"XD H17 3:2",
+		"6D H17 3:2 SP1",
+		"6D H17 3:2 PP6/12",
+		"6D H17 3:2 EM",
+		"6D H17 3:2 3CC",
+		"6D H17 3:2 MIN=10 MIN=20",
+		"6D H17 3:2 MIN=X",
+		"6D H17 3:2 WIN=2",
+		"6D H17 3:2 ROUND=UP",
+		"6D H17 3:2 HAND5=2:1",
+		"6D H17 3:2 FOO=1",
+		"6D H17 3:2 MIN=-5",
+	}
+
+	for _, s := range cases {
+		if _, err := ParseRules(s); err == nil {
+			t.Errorf("%q: no error", s)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package blackjack
+
+// Observer observes the events at a table.
+type Observer interface {
+	Observe(e Event)
+}
+
+// ObserverFunc is an Observer that calls the function.
+type ObserverFunc func(e Event)
+
+// Observe calls f(e).
+func (f ObserverFunc) Observe(e Event) {
+	f(e)
+}
+
+type subscription struct {
+	o Observer
+}
+
+// Subscribe lets observer o observe every event at the table, until the
+// returned function is called. Observers are called in the order they
+// subscribed, when the event happens and before it is reported to the
+// UI of the players.
+func (t *Table) Subscribe(o Observer) (unsubscribe func()) {
+	s := &subscription{o: o}
+	t.observers = append(t.observers, s)
+	return func() {
+		// Copy the observers so an observer can unsubscribe while the
+		// observers are notified.
+		var observers []*subscription
+		for _, other := range t.observers {
+			if other != s {
+				observers = append(observers, other)
+			}
+		}
+		t.observers = observers
+	}
+}
+
+// notify sends event e to the observers of the table.
+func (t *table) notify(e Event) {
+	for _, s := range t.observers {
+		s.o.Observe(e)
+	}
+}
@@ -0,0 +1,91 @@
+package blackjack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestObserver(t *testing.T) {
+	dealer := Hand{card.Diamond(card.Seven), card.Diamond(card.Jack)}
+	first := Hand{card.Club(card.Ten), card.Club(card.Queen)}
+	second := Hand{card.Club(card.Two), card.Club(card.Five), card.Spade(card.Nine)}
+
+	want := []Event{
+		MoveEvent{Seat: 1, Phase: PhaseBet, Move: Move{Amount: decimal.New(10, 0)}},
+		BetEvent{Seat: 1, Kind: MainBet, Amount: decimal.New(10, 0)},
+		MoveEvent{Seat: 4, Phase: PhaseBet, Move: Move{Amount: decimal.New(10, 0)}},
+		BetEvent{Seat: 4, Kind: MainBet, Amount: decimal.New(10, 0)},
+		RoundStartEvent{Round: 1, Seats: []int{1, 4}},
+		CardEvent{Seat: 1, Card: first[0]},
+		CardEvent{Seat: 4, Card: second[0]},
+		CardEvent{Seat: DealerSeat, Card: dealer[0]},
+		CardEvent{Seat: 1, Card: first[1]},
+		CardEvent{Seat: 4, Card: second[1]},
+		HandEvent{Seat: 1, Dealer: DealerHand{hand: dealer[:1]}, Player: first},
+		MoveEvent{Seat: 1, Phase: PhaseAction, Allowed: []Action{Hit, Stand, Double}, Move: Move{Action: Stand}},
+		ActionEvent{Seat: 1, Action: Stand},
+		HandEvent{Seat: 4, Dealer: DealerHand{hand: dealer[:1]}, Player: second[:2]},
+		MoveEvent{Seat: 4, Phase: PhaseAction, Allowed: []Action{Hit, Stand, Double}, Move: Move{Action: Hit}},
+		ActionEvent{Seat: 4, Action: Hit},
+		CardEvent{Seat: 4, Card: second[2]},
+		HandEvent{Seat: 4, Dealer: DealerHand{hand: dealer[:1]}, Player: second},
+		MoveEvent{Seat: 4, Phase: PhaseAction, Allowed: []Action{Hit, Stand}, Move: Move{Action: Stand}},
+		ActionEvent{Seat: 4, Action: Stand},
+		CardEvent{Seat: DealerSeat, Card: dealer[1]},
+		DealerCardEvent{Card: dealer[1], Dealer: dealer},
+		OutcomeEvent{Seat: 1, Outcome: Won, Amount: decimal.New(20, 0), Dealer: dealer, Player: first},
+		OutcomeEvent{Seat: 4, Outcome: Lost, Amount: decimal.New(-10, 0), Dealer: dealer, Player: second},
+		ShuffleEvent{Cards: []card.Card{dealer[0], dealer[1], first[0], first[1], second[0], second[1], second[2]}},
+		RoundEndEvent{Round: 1},
+		MoveEvent{Seat: 1, Phase: PhaseNewGame, Move: Move{Accept: false}},
+		MoveEvent{Seat: 4, Phase: PhaseNewGame, Move: Move{Accept: false}},
+	}
+
+	seededShuffler(27, func() {
+		tbl := NewTable(testRules{})
+		tbl.Sit(1, player.NewFortune(decimal.New(50, 0)))
+		tbl.Sit(4, player.NewFortune(decimal.New(50, 0)))
+
+		var got, stopped []Event
+		tbl.Subscribe(ObserverFunc(func(e Event) {
+			got = append(got, e)
+		}))
+		var unsubscribe func()
+		unsubscribe = tbl.Subscribe(ObserverFunc(func(e Event) {
+			stopped = append(stopped, e)
+			if _, ok := e.(RoundStartEvent); ok {
+				unsubscribe()
+			}
+		}))
+
+		moves := []Move{
+			{Amount: decimal.New(10, 0)},
+			{Amount: decimal.New(10, 0)},
+			{Action: Stand},
+			{Action: Hit},
+			{Action: Stand},
+			{Accept: false},
+			{Accept: false},
+		}
+		for _, m := range moves {
+			mustApply(t, tbl, m)
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("got %d events %v, want: %d events %v", len(got), got, len(want), want)
+		}
+		for i, e := range got {
+			if !reflect.DeepEqual(e, want[i]) {
+				t.Errorf("#%d: got event %+v, want: %+v", i+1, e, want[i])
+			}
+		}
+		if len(stopped) != 5 {
+			t.Errorf("got %d events before unsubscribe, want: 5", len(stopped))
+		}
+	})
+}
@@ -4,9 +4,9 @@ package blackjack
 
 import "fmt"
 
-const _Outcome_name = "WonLostBustPushedSurrenderedBlackjackDealerBlackjack"
+const _Outcome_name = "WonLostBustPushedSurrenderedBlackjackDealerBlackjackEvenMoneyBonus"
 
-var _Outcome_index = [...]uint8{0, 3, 7, 11, 17, 28, 37, 52}
+var _Outcome_index = [...]uint8{0, 3, 7, 11, 17, 28, 37, 52, 61, 66}
 
 func (i Outcome) String() string {
 	if i < 0 || i >= Outcome(len(_Outcome_index)-1) {
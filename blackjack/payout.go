@@ -0,0 +1,157 @@
+package blackjack
+
+import "github.com/shopspring/decimal"
+
+// Payouts is the payout table of a game, ratios are the winnings paid
+// per unit staked. A zero ratio means the standard payout, a blackjack is
+// paid by the blackjack ratio of the rules.
+type Payouts struct {
+	// SuitedBlackjack is paid instead of the blackjack ratio for a
+	// blackjack of one suit.
+	SuitedBlackjack decimal.Decimal `json:"suitedBlackjack" toml:"suitedBlackjack" yaml:"suitedBlackjack"`
+
+	Win       decimal.Decimal `json:"win" toml:"win" yaml:"win"`                   // 1:1
+	Surrender decimal.Decimal `json:"surrender" toml:"surrender" yaml:"surrender"` // half the stake returned
+	Insurance decimal.Decimal `json:"insurance" toml:"insurance" yaml:"insurance"` // 2:1
+
+	// Hands pay a different win ratio for particular winning hands,
+	// the highest matching ratio is paid.
+	Hands []HandPayout `json:"hands,omitempty" toml:"hands,omitempty" yaml:"hands,omitempty"`
+}
+
+// HandPayout pays a win ratio for a winning hand that has at least a
+// number of cards, a total or is of one suit. Zero fields match any hand.
+type HandPayout struct {
+	Cards  int             `json:"cards" toml:"cards" yaml:"cards"`
+	Total  int             `json:"total" toml:"total" yaml:"total"`
+	Suited bool            `json:"suited" toml:"suited" yaml:"suited"`
+	Ratio  decimal.Decimal `json:"ratio" toml:"ratio" yaml:"ratio"`
+}
+
+func (hp HandPayout) matches(h Hand) bool {
+	if len(h) < hp.Cards {
+		return false
+	}
+	if hp.Total > 0 && h.Value().Total() != hp.Total {
+		return false
+	}
+	return !hp.Suited || h.suited()
+}
+
+func (h Hand) suited() bool {
+	for _, c := range h[1:] {
+		if c.Suit != h[0].Suit {
+			return false
+		}
+	}
+	return len(h) > 0
+}
+
+// valid returns true if all ratios are not negative and a surrender
+// doesn't return more than the stake.
+func (p Payouts) valid() bool {
+	ratios := []decimal.Decimal{p.SuitedBlackjack, p.Win, p.Surrender, p.Insurance}
+	for _, hp := range p.Hands {
+		ratios = append(ratios, hp.Ratio)
+	}
+	for _, r := range ratios {
+		if r.Sign() < 0 {
+			return false
+		}
+	}
+	return p.Surrender.Cmp(decimal.New(1, 0)) <= 0
+}
+
+func orDefault(d, def decimal.Decimal) decimal.Decimal {
+	if d.IsZero() {
+		return def
+	}
+	return d
+}
+
+// win returns the win ratio of winning hand h.
+func (p Payouts) win(h Hand) decimal.Decimal {
+	ratio := orDefault(p.Win, decimal.New(1, 0))
+	for _, hp := range p.Hands {
+		if hp.matches(h) && hp.Ratio.Cmp(ratio) > 0 {
+			ratio = hp.Ratio
+		}
+	}
+	return ratio
+}
+
+func (p Payouts) surrender() decimal.Decimal {
+	return orDefault(p.Surrender, decimal.New(5, -1))
+}
+
+func (p Payouts) insurance() decimal.Decimal {
+	return orDefault(p.Insurance, decimal.New(2, 0))
+}
+
+// blackjackRatio returns the ratio paid for blackjack hand h.
+func (g *game) blackjackRatio(h Hand) decimal.Decimal {
+	if p := g.rules.Payouts(); h.suited() && !p.SuitedBlackjack.IsZero() {
+		return p.SuitedBlackjack
+	}
+	return g.rules.BlackjackRatio()
+}
+
+// settle settles a stake of bet b with outcome o. It deposits the payout
+// and reports the outcome, lost stakes are reported negative except for a
+// dealer blackjack. A bonus is paid by the bonus hand recorded on the bet.
+// It returns the net result of the stake.
+func (g *game) settle(o Outcome, stake decimal.Decimal, b *bet) decimal.Decimal {
+	var amount decimal.Decimal
+	switch o {
+	case Won:
+		amount = g.payout(stake, stake.Mul(g.rules.Payouts().win(b.hand)))
+	case Blackjack:
+		amount = g.payout(stake, stake.Mul(g.blackjackRatio(b.hand)))
+	case Bonus:
+		amount = g.payout(stake, stake.Mul(g.rules.Bonuses().ratio(b.bonus)))
+	case EvenMoney:
+		amount = g.payout(stake, stake)
+	case Pushed:
+		amount = stake
+	case Surrendered:
+		amount = g.roundPayout(stake.Mul(g.rules.Payouts().surrender()))
+	case DealerBlackjack:
+		amount = stake
+	default:
+		amount = decimal.Zero.Sub(stake)
+	}
+
+	deposited := decimal.Zero
+	if amount.Sign() > 0 && o != DealerBlackjack {
+		deposited = amount
+		g.fortune.Deposit(amount)
+	}
+	e := OutcomeEvent{Seat: g.seat, Outcome: o, Amount: amount, Dealer: g.dealer, Player: b.hand}
+	if o == Bonus {
+		e.Bonus = b.bonus
+	}
+	g.emit(e)
+	return deposited.Sub(stake)
+}
+
+// settleBet settles bet b with outcome o.
+func (g *game) settleBet(o Outcome, b *bet) {
+	g.sum.hand(o, g.settle(o, b.amount, b))
+	b.settled = true
+}
+
+// payout returns the stake with the winnings, which are rounded and
+// capped by the maximum payout of the table.
+func (g *game) payout(stake, winnings decimal.Decimal) decimal.Decimal {
+	winnings = g.roundPayout(winnings)
+	if max := g.rules.Limits().MaxPayout; max.Sign() > 0 && winnings.Cmp(max) > 0 {
+		winnings = max
+	}
+	return stake.Add(winnings)
+}
+
+// roundPayout rounds payout d by the rounding policy of the table.
+func (g *game) roundPayout(d decimal.Decimal) decimal.Decimal {
+	l := g.rules.Limits()
+	return l.Rounding.round(d, l.Unit)
+}
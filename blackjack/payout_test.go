@@ -0,0 +1,101 @@
+package blackjack
+
+import (
+	"testing"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSettle(t *testing.T) {
+	mustParse := func(s string) *RuleSet {
+		rs, err := ParseRules(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rs
+	}
+
+	payouts := func(rs *RuleSet, p Payouts) *RuleSet {
+		rs.PayoutTable = p
+		return rs
+	}
+
+	bj := Hand{card.Spade(card.Ace), card.Heart(card.King)}
+	suitedBJ := Hand{card.Spade(card.Ace), card.Spade(card.King)}
+	twenty := Hand{card.Club(card.Queen), card.Diamond(card.King)}
+	fiveCard21 := Hand{
+		card.Club(card.Two), card.Diamond(card.Three), card.Heart(card.Four),
+		card.Club(card.Five), card.Spade(card.Seven),
+	}
+
+	bonus := Payouts{
+		SuitedBlackjack: decimal.New(2, 0),
+		Win:             decimal.New(1, 0),
+		Surrender:       decimal.New(25, -2),
+		Hands: []HandPayout{
+			{Cards: 5, Total: 21, Ratio: decimal.New(2, 0)},
+			{Cards: 5, Ratio: decimal.New(15, -1)},
+		},
+	}
+
+	cases := []struct {
+		name    string
+		rules   Rules
+		outcome Outcome
+		stake   decimal.Decimal
+		hand    Hand
+		want    decimal.Decimal
+	}{
+		{"Blackjack/3:2", mustParse("6D H17 3:2"), Blackjack, decimal.New(10, 0), bj, decimal.New(25, 0)},
+		{"Blackjack/6:5", mustParse("6D H17 6:5"), Blackjack, decimal.New(10, 0), bj, decimal.New(22, 0)},
+		{"Blackjack/7:5", mustParse("6D H17 7:5"), Blackjack, decimal.New(10, 0), bj, decimal.New(24, 0)},
+		{"Blackjack/1:1", mustParse("6D H17 1:1"), Blackjack, decimal.New(10, 0), bj, decimal.New(20, 0)},
+		{"Blackjack/Exact", mustParse("6D H17 3:2"), Blackjack, decimal.New(5, 0), bj, decimal.New(125, -1)},
+		{"Blackjack/Suited", payouts(mustParse("6D H17 3:2"), bonus), Blackjack, decimal.New(10, 0), suitedBJ, decimal.New(30, 0)},
+		{"Blackjack/NotSuited", payouts(mustParse("6D H17 3:2"), bonus), Blackjack, decimal.New(10, 0), bj, decimal.New(25, 0)},
+		{"Won", testRules{}, Won, decimal.New(10, 0), twenty, decimal.New(20, 0)},
+		{"Won/Exact", testRules{payouts: Payouts{Win: decimal.New(9, -1)}}, Won, decimal.New(5, -2), twenty, decimal.New(95, -3)},
+		{"Won/Hand", testRules{payouts: bonus}, Won, decimal.New(10, 0), fiveCard21, decimal.New(30, 0)},
+		{"Won/NotHand", testRules{payouts: bonus}, Won, decimal.New(10, 0), twenty, decimal.New(20, 0)},
+		{"Surrendered", testRules{}, Surrendered, decimal.New(10, 0), twenty, decimal.New(5, 0)},
+		{"Surrendered/Quarter", testRules{payouts: bonus}, Surrendered, decimal.New(10, 0), twenty, decimal.New(25, -1)},
+		{"EvenMoney", testRules{}, EvenMoney, decimal.New(10, 0), bj, decimal.New(20, 0)},
+		{"Pushed", testRules{}, Pushed, decimal.New(10, 0), twenty, decimal.New(10, 0)},
+		{"Lost", testRules{}, Lost, decimal.New(10, 0), twenty, decimal.Zero},
+		{"DealerBlackjack", testRules{}, DealerBlackjack, decimal.New(10, 0), twenty, decimal.Zero},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := player.NewFortune(decimal.Zero)
+			g := &game{table: &table{rules: c.rules}, ui: settleUI{}, fortune: f}
+			g.settle(c.outcome, c.stake, &bet{hand: c.hand})
+
+			if !f.Active().Equal(c.want) {
+				t.Errorf("got payout %v, want: %v", f.Active(), c.want)
+			}
+		})
+	}
+}
+
+func TestPayoutsValid(t *testing.T) {
+	cases := []struct {
+		p    Payouts
+		want bool
+	}{
+		{Payouts{}, true},
+		{Payouts{Surrender: decimal.New(1, 0)}, true},
+		{Payouts{Surrender: decimal.New(11, -1)}, false},
+		{Payouts{Win: decimal.New(-1, 0)}, false},
+		{Payouts{Hands: []HandPayout{{Ratio: decimal.New(-2, 0)}}}, false},
+	}
+
+	for _, c := range cases {
+		if got := c.p.valid(); got != c.want {
+			t.Errorf("%+v: got: %v, want: %v", c.p, got, c.want)
+		}
+	}
+}
@@ -28,13 +28,21 @@ func (h Hand) perfectPair() PerfectPair {
 	return Mixed
 }
 
-func (g *game) perfectPair() {
-	amount := g.ui.PerfectPairBet(g.fortune)
-	if amount.Equal(decimal.Zero) || !g.fortune.Has(amount) {
+// perfectPair places a perfect pair side bet of amount and pays it at
+// once, a zero amount declines the bet.
+func (g *game) perfectPair(amount decimal.Decimal) {
+	if amount.Equal(decimal.Zero) {
+		return
+	}
+	if err := g.checkBet(SideBet, amount); err != nil {
+		g.emit(BetRejectedEvent{Seat: g.seat, Err: err})
 		return
 	}
 
 	g.fortune.Withdrawal(amount)
+	g.notify(BetEvent{Seat: g.seat, Kind: SideBet, Amount: amount})
+	stake, deposited := amount, decimal.Zero
+	defer func() { g.sum.side(&g.sum.PerfectPair, stake, deposited) }()
 
 	b := g.bets[0]
 	pp := b.hand.perfectPair()
@@ -53,9 +61,10 @@ func (g *game) perfectPair() {
 
 		if factor > 0 {
 			factor := decimal.New(int64(factor), 0)
-			amount = amount.Mul(factor)
+			amount = g.payout(decimal.Zero, amount.Mul(factor))
 			g.fortune.Deposit(amount)
-			g.ui.PerfectPair(pp, amount)
+			deposited = amount
+			g.emit(PerfectPairEvent{Seat: g.seat, Kind: pp, Amount: amount})
 		}
 	}
 }
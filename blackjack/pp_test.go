@@ -8,111 +8,134 @@ import (
 )
 
 func TestMixedPerfectPairGame(t *testing.T) {
-	testPlay(t, 2, HollandCasino, 10, 5, []event{
+	testPlay(t, 139, HollandCasino, 10, 5, []event{
 		perfectPair{Mixed, decimal.New(30, 0)},
 		hand{
-			dealer: Hand{card.Diamond(card.Six)},
-			player: Hand{card.Heart(card.Two), card.Spade(card.Two)},
+			dealer: Hand{card.Heart(card.Three)},
+			player: Hand{card.Diamond(card.Two), card.Club(card.Two)},
 		},
 		nextAction{[]Action{Hit, Stand, Split}, Stand},
 		dealerCard{
-			card: card.Spade(card.Queen),
-			hand: Hand{card.Diamond(card.Six), card.Spade(card.Queen)},
+			card: card.Heart(card.Seven),
+			hand: Hand{card.Heart(card.Three), card.Heart(card.Seven)},
 		},
 		dealerCard{
-			card: card.Diamond(card.Eight),
+			card: card.Spade(card.Two),
 			hand: Hand{
-				card.Diamond(card.Six),
-				card.Spade(card.Queen),
-				card.Diamond(card.Eight),
+				card.Heart(card.Three),
+				card.Heart(card.Seven),
+				card.Spade(card.Two),
+			},
+		},
+		dealerCard{
+			card: card.Spade(card.Four),
+			hand: Hand{
+				card.Heart(card.Three),
+				card.Heart(card.Seven),
+				card.Spade(card.Two),
+				card.Spade(card.Four),
+			},
+		},
+		dealerCard{
+			card: card.Heart(card.Queen),
+			hand: Hand{
+				card.Heart(card.Three),
+				card.Heart(card.Seven),
+				card.Spade(card.Two),
+				card.Spade(card.Four),
+				card.Heart(card.Queen),
 			},
 		},
 		outcome{
 			outcome: Won,
 			amount:  decimal.New(20, 0),
 			dealer: Hand{
-				card.Diamond(card.Six),
-				card.Spade(card.Queen),
-				card.Diamond(card.Eight),
+				card.Heart(card.Three),
+				card.Heart(card.Seven),
+				card.Spade(card.Two),
+				card.Spade(card.Four),
+				card.Heart(card.Queen),
 			},
-			player: Hand{card.Heart(card.Two), card.Spade(card.Two)},
+			player: Hand{card.Diamond(card.Two), card.Club(card.Two)},
 		},
 	})
 }
 
 func TestSamePerfectPairGame(t *testing.T) {
-	testPlay(t, 107, HollandCasino, 10, 5, []event{
+	testPlay(t, 52, HollandCasino, 10, 5, []event{
 		perfectPair{Same, decimal.New(60, 0)},
 		hand{
-			dealer: Hand{card.Heart(card.Ace)},
-			player: Hand{card.Club(card.Ace), card.Spade(card.Ace)},
+			dealer: Hand{card.Spade(card.Six)},
+			player: Hand{card.Club(card.Four), card.Spade(card.Four)},
 		},
 		nextAction{[]Action{Hit, Stand, Split}, Hit},
 		hand{
-			dealer: Hand{card.Heart(card.Ace)},
+			dealer: Hand{card.Spade(card.Six)},
 			player: Hand{
-				card.Club(card.Ace),
-				card.Spade(card.Ace),
-				card.Club(card.Seven),
+				card.Club(card.Four),
+				card.Spade(card.Four),
+				card.Spade(card.Four),
 			},
 		},
 		nextAction{[]Action{Hit, Stand}, Stand},
 		dealerCard{
-			card: card.Spade(card.Eight),
-			hand: Hand{card.Heart(card.Ace), card.Spade(card.Eight)},
+			card: card.Spade(card.Six),
+			hand: Hand{card.Spade(card.Six), card.Spade(card.Six)},
+		},
+		dealerCard{
+			card: card.Spade(card.Seven),
+			hand: Hand{
+				card.Spade(card.Six),
+				card.Spade(card.Six),
+				card.Spade(card.Seven),
+			},
 		},
 		outcome{
-			outcome: Pushed,
-			amount:  decimal.New(10, 0),
-			dealer:  Hand{card.Heart(card.Ace), card.Spade(card.Eight)},
+			outcome: Lost,
+			amount:  decimal.New(-10, 0),
+			dealer: Hand{
+				card.Spade(card.Six),
+				card.Spade(card.Six),
+				card.Spade(card.Seven),
+			},
 			player: Hand{
-				card.Club(card.Ace),
-				card.Spade(card.Ace),
-				card.Club(card.Seven),
+				card.Club(card.Four),
+				card.Spade(card.Four),
+				card.Spade(card.Four),
 			},
 		},
 	})
 }
 
 func TestPerfectPerfectPairGame(t *testing.T) {
-	testPlay(t, 38, HollandCasino, 10, 5, []event{
+	testPlay(t, 123, HollandCasino, 10, 5, []event{
 		perfectPair{Perfect, decimal.New(125, 0)},
 		hand{
-			dealer: Hand{card.Diamond(card.Nine)},
-			player: Hand{card.Diamond(card.Ten), card.Diamond(card.Ten)},
+			dealer: Hand{card.Heart(card.King)},
+			player: Hand{card.Diamond(card.Three), card.Diamond(card.Three)},
 		},
 		nextAction{[]Action{Hit, Stand, Split}, Stand},
 		dealerCard{
-			card: card.Spade(card.Four),
-			hand: Hand{card.Diamond(card.Nine), card.Spade(card.Four)},
+			card: card.Heart(card.Five),
+			hand: Hand{card.Heart(card.King), card.Heart(card.Five)},
 		},
 		dealerCard{
-			card: card.Spade(card.Ace),
+			card: card.Diamond(card.Ten),
 			hand: Hand{
-				card.Diamond(card.Nine),
-				card.Spade(card.Four),
-				card.Spade(card.Ace),
-			},
-		},
-		dealerCard{
-			card: card.Diamond(card.Nine),
-			hand: Hand{
-				card.Diamond(card.Nine),
-				card.Spade(card.Four),
-				card.Spade(card.Ace),
-				card.Diamond(card.Nine),
+				card.Heart(card.King),
+				card.Heart(card.Five),
+				card.Diamond(card.Ten),
 			},
 		},
 		outcome{
 			outcome: Won,
 			amount:  decimal.New(20, 0),
 			dealer: Hand{
-				card.Diamond(card.Nine),
-				card.Spade(card.Four),
-				card.Spade(card.Ace),
-				card.Diamond(card.Nine),
+				card.Heart(card.King),
+				card.Heart(card.Five),
+				card.Diamond(card.Ten),
 			},
-			player: Hand{card.Diamond(card.Ten), card.Diamond(card.Ten)},
+			player: Hand{card.Diamond(card.Three), card.Diamond(card.Three)},
 		},
 	})
 }
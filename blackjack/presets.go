@@ -0,0 +1,80 @@
+package blackjack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Common game rules, the rules of individual casino's may differ.
+var (
+	LasVegasStrip Rules = newPreset("Las Vegas Strip",
+		"Six deck shoe on the Las Vegas Strip, dealer stands on soft 17.",
+		"6D S17 LS SP4 DOA DAS PEEK 3:2 INS EM")
+	DowntownSingleDeck Rules = newPreset("Downtown Single Deck",
+		"Hand dealt single deck in downtown Las Vegas, split once, no double after split.",
+		"1D H17 SP2 DOA PEEK 3:2 INS EM")
+	DowntownDoubleDeck Rules = newPreset("Downtown Double Deck",
+		"Hand dealt double deck in downtown Las Vegas.",
+		"2D H17 SP4 DOA DAS PEEK 3:2 INS EM")
+	AtlanticCity Rules = newPreset("Atlantic City",
+		"Eight deck shoe in Atlantic City with late surrender.",
+		"8D S17 LS SP4 DOA DAS PEEK 3:2 INS EM")
+	Reno Rules = newPreset("Reno",
+		"Six deck shoe in Reno, doubling on 10 and 11 only.",
+		"6D H17 SP4 D10 DAS PEEK 3:2 INS EM")
+	European Rules = newPreset("European",
+		"European and UK game without a hole card, doubling on 9 to 11 only.",
+		"6D S17 SP2 D9 DAS ENHC 3:2 INS EM")
+	Macau Rules = newPreset("Macau",
+		"Macau game without a hole card and early surrender, except against an ace.",
+		"6D S17 ES10 SP4 DOA DAS ENHC 3:2 INS EM")
+	OnlineCSM Rules = newPreset("Online CSM",
+		"Online eight deck game with a continuous shuffling machine.",
+		"8D S17 SP2 DOA DAS PEEK 3:2 INS EM")
+)
+
+// preset is a rule set of AvailableRules, which can't be changed because
+// the rule set is not exported.
+type preset struct{ *RuleSet }
+
+func newPreset(name, desc, notation string) preset {
+	rs, err := ParseRules(notation)
+	if err != nil {
+		panic(err)
+	}
+	rs.Name = name
+	rs.Description = desc
+	return preset{rs}
+}
+
+// LookupRules returns the available game rules with the given name,
+// names are matched case insensitive.
+func LookupRules(name string) (Rules, bool) {
+	for _, r := range AvailableRules {
+		if strings.EqualFold(RulesName(r), name) {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// RulesName returns the name of the game rules r, rules without a name
+// are named by their notation.
+func RulesName(r Rules) string {
+	if s, ok := r.(fmt.Stringer); ok && s.String() != "" {
+		return s.String()
+	}
+	return FormatRules(r)
+}
+
+// RulesDescription returns the description of game rules r, rules without
+// a description return an empty string.
+func RulesDescription(r Rules) string {
+	switch r := r.(type) {
+	case *RuleSet:
+		return r.Description
+	case preset:
+		return r.Description
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package blackjack
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPresets(t *testing.T) {
+	cases := []struct {
+		rules     Rules
+		name      string
+		decks     uint
+		h17       bool
+		das       bool
+		surrender SurrenderRule
+		hands     int
+		double    DoubleRule
+		noHole    bool
+	}{
+		{LasVegasStrip, "Las Vegas Strip", 6, false, true, LateSurrender, 4, DoubleAny, false},
+		{DowntownSingleDeck, "Downtown Single Deck", 1, true, false, NoSurrender, 2, DoubleAny, false},
+		{DowntownDoubleDeck, "Downtown Double Deck", 2, true, true, NoSurrender, 4, DoubleAny, false},
+		{AtlanticCity, "Atlantic City", 8, false, true, LateSurrender, 4, DoubleAny, false},
+		{Reno, "Reno", 6, true, true, NoSurrender, 4, DoubleOnly10_11, false},
+		{European, "European", 6, false, true, NoSurrender, 2, DoubleOnly9_10_11, true},
+		{Macau, "Macau", 6, false, true, EarlySurrender, 4, DoubleAny, true},
+		{OnlineCSM, "Online CSM", 8, false, true, NoSurrender, 2, DoubleAny, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := c.rules
+			if got := RulesName(r); got != c.name {
+				t.Errorf("got name %q, want: %q", got, c.name)
+			}
+			if RulesDescription(r) == "" {
+				t.Error("no description")
+			}
+			if got := r.NumDecks(); got != c.decks {
+				t.Errorf("got %d decks, want: %d", got, c.decks)
+			}
+			if got := r.DealerHitSoft17(); got != c.h17 {
+				t.Errorf("got hit soft 17 %v, want: %v", got, c.h17)
+			}
+			if got := r.DoubleAfterSplit(); got != c.das {
+				t.Errorf("got double after split %v, want: %v", got, c.das)
+			}
+			if got := r.Surrender(); got != c.surrender {
+				t.Errorf("got surrender %v, want: %v", got, c.surrender)
+			}
+			if got := r.SplitHands(); got != c.hands {
+				t.Errorf("got %d split hands, want: %d", got, c.hands)
+			}
+			if got := r.Double(); got != c.double {
+				t.Errorf("got double %v, want: %v", got, c.double)
+			}
+			if got := r.NoHoleCard(); got != c.noHole {
+				t.Errorf("got no hole card %v, want: %v", got, c.noHole)
+			}
+			if got := r.Peek() != NoPeek; got == c.noHole {
+				t.Errorf("got peek %v, want: %v", r.Peek(), !c.noHole)
+			}
+			if got := r.BlackjackRatio(); !got.Equal(decimal.New(15, -1)) {
+				t.Errorf("got blackjack ratio %v, want: 1.5", got)
+			}
+			if !r.Insurance() || !r.EvenMoney() {
+				t.Error("got no insurance or even money")
+			}
+			if err := NewRuleSet(c.name, r).Validate(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestPresetsShared(t *testing.T) {
+	// A preset can only be changed by a copy of its rules.
+	r, _ := LookupRules("Reno")
+	if _, ok := r.(*RuleSet); ok {
+		t.Fatal("got a *RuleSet that can be changed")
+	}
+	rs := NewRuleSet("Changed", r)
+	rs.Decks = 1
+	if got := Reno.NumDecks(); got != 6 {
+		t.Errorf("got %d decks, want: 6", got)
+	}
+}
+
+func TestAvailableRules(t *testing.T) {
+	names := make(map[string]bool)
+	for _, r := range AvailableRules {
+		name := RulesName(r)
+		if names[name] {
+			t.Errorf("duplicate rules %q", name)
+		}
+		names[name] = true
+
+		if err := NewRuleSet(name, r).Validate(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestLookupRules(t *testing.T) {
+	cases := []struct {
+		name string
+		want Rules
+	}{
+		{"Holland Casino", HollandCasino},
+		{"taptapboom", TapTapBoom},
+		{"LAS VEGAS STRIP", LasVegasStrip},
+		{"Macau", Macau},
+	}
+
+	for _, c := range cases {
+		got, ok := LookupRules(c.name)
+		if !ok || got != c.want {
+			t.Errorf("%q: got: %v, want: %v", c.name, got, c.want)
+		}
+	}
+
+	if r, ok := LookupRules("Monte Carlo"); ok {
+		t.Errorf("got: %v, want none", r)
+	}
+}
+
+func TestRulesName(t *testing.T) {
+	if got, want := RulesName(HollandCasino), "Holland Casino"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+
+	rs := NewRuleSet("", Reno)
+	if got, want := RulesName(rs), FormatRules(Reno); got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,384 @@
+package blackjack
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReplayUI is a UI that shows a replay. Replay is called with every
+// recorded move before it is made, so the UI can show the move or wait
+// before going on.
+type ReplayUI interface {
+	UI
+	Replay(m HistoryMove)
+}
+
+// ReplayError reports the first difference between a replayed game and
+// its hand history. Got and Want are the HistoryCard, HistoryMove or
+// HistoryResult at Index of the round, or nil when it is missing. A
+// recorded move the table doesn't accept has the *MoveError as Got.
+type ReplayError struct {
+	Round int
+	What  string // card, move, result or round
+	Index int
+	Got   interface{}
+	Want  interface{}
+}
+
+func (e *ReplayError) Error() string {
+	return fmt.Sprintf("blackjack: replay of round %d diverged at %s %d: got %+v, want: %+v",
+		e.Round, e.What, e.Index, e.Got, e.Want)
+}
+
+// Replay plays the rounds of hand history hs again, like Table.Play, with
+// the cards dealt and the moves made as recorded. It checks that the game
+// goes as recorded and returns a *ReplayError at the first difference.
+// A history with moves of seats that don't exist or players that can't
+// join is malformed and returns another error.
+//
+// The game is played with rules r, or with the GameRules of the history
+// if r is nil. Players join with the fortune they had at their first move,
+// the boxes of a player join together.
+// When ui is not nil, the events of the game are reported to it.
+func Replay(hs []*HandHistory, r Rules, ui UI) error {
+	if len(hs) == 0 {
+		return nil
+	}
+	if r == nil {
+		var err error
+		if r, err = hs[0].GameRules(); err != nil {
+			return err
+		}
+	}
+
+	for _, h := range hs {
+		for _, m := range h.Moves {
+			if m.Seat < 0 || m.Seat >= MaxSeats {
+				return fmt.Errorf("blackjack: replay of round %d: %v: %d", h.Round, ErrNoSeat, m.Seat)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t := NewSeededTable(r, hs[0].Seed)
+	rp := &replay{hs: hs, cancel: cancel}
+	rp.rec = recorder{t: t, done: rp.done}
+	rp.display(ui)
+
+	var cards replayShoe
+	for _, h := range hs {
+		for _, c := range h.Cards {
+			cards = append(cards, c.Card)
+		}
+	}
+	t.shuffler = &cards
+
+	t.Subscribe(ObserverFunc(rp.observe))
+	for rp.round < len(hs) && rp.err == nil {
+		if err := rp.join(t, hs[rp.round]); err != nil {
+			return err
+		}
+
+		more, err := t.PlayRoundContext(ctx)
+		if rp.err != nil {
+			return rp.err
+		}
+		if err == ErrNoCards {
+			i := 0
+			if rp.rec.h != nil {
+				i = len(rp.rec.h.Cards)
+			}
+			rp.diverged("card", i, nil, nil)
+			return rp.err
+		}
+		if me, ok := err.(*MoveError); ok {
+			i := 0
+			if rp.rec.h != nil {
+				i = len(rp.rec.h.Moves)
+			}
+			var want interface{}
+			if moves := hs[rp.round].Moves; i < len(moves) {
+				want = moves[i]
+			}
+			rp.diverged("move", i, me, want)
+			return rp.err
+		}
+		if err != nil {
+			return err
+		}
+		if !more && rp.round < len(hs) {
+			rp.diverged("round", 0, nil, hs[rp.round].Round)
+		}
+	}
+	return rp.err
+}
+
+// join seats the players that make their first move in round h.
+func (rp *replay) join(t *Table, h *HandHistory) error {
+	for _, m := range h.Moves {
+		if m.Phase != PhaseBet || t.seats[m.Seat] != nil {
+			continue
+		}
+
+		f := player.NewFortune(m.Fortune)
+		seats := []int{m.Seat}
+		for _, boxes := range h.Boxes {
+			for _, seat := range boxes {
+				if seat == m.Seat {
+					seats = boxes
+				}
+			}
+		}
+		var err error
+		if len(seats) > 1 {
+			err = t.JoinBoxes(replayUI{rp}, f, seats...)
+		} else {
+			err = t.Join(m.Seat, replayUI{rp}, f)
+		}
+		if err != nil {
+			return fmt.Errorf("blackjack: replay of round %d: %v: %v", h.Round, err, seats)
+		}
+	}
+	return nil
+}
+
+// replay checks a replayed game against its hand history.
+type replay struct {
+	hs     []*HandHistory
+	round  int // index of the round in play
+	rec    recorder
+	err    error
+	cancel func()
+
+	ui   ReplayUI
+	uis  [MaxSeats]UI // UI at every seat to report the events to
+	disp []UI         // UI to report the dealer events to
+}
+
+// display reports the events of the game to ui.
+func (rp *replay) display(ui UI) {
+	if ui == nil {
+		return
+	}
+	rp.ui, _ = ui.(ReplayUI)
+	rp.disp = []UI{ui}
+
+	bui, ok := ui.(BoxUI)
+	var p *boxes
+	if ok {
+		p = &boxes{ui: bui, seat: -1}
+	}
+	for i := range rp.uis {
+		rp.uis[i] = ui
+		if ok {
+			rp.uis[i] = boxUI{p, i}
+		}
+	}
+}
+
+// want returns the recorded round in play, or nil when all rounds are
+// replayed.
+func (rp *replay) want() *HandHistory {
+	if rp.round < len(rp.hs) {
+		return rp.hs[rp.round]
+	}
+	return nil
+}
+
+// next returns the next recorded move, it returns false when there is no
+// move left.
+func (rp *replay) next() (HistoryMove, bool) {
+	want := rp.want()
+	if want == nil || rp.err != nil {
+		return HistoryMove{}, false
+	}
+	i := 0
+	if rp.rec.h != nil {
+		i = len(rp.rec.h.Moves)
+	}
+	if i >= len(want.Moves) {
+		return HistoryMove{}, false
+	}
+
+	m := want.Moves[i]
+	if rp.ui != nil {
+		rp.ui.Replay(m)
+	}
+	return m, true
+}
+
+func (rp *replay) observe(e Event) {
+	if rp.err != nil {
+		return
+	}
+	rp.rec.observe(e)
+	if h := rp.rec.h; h != nil {
+		rp.check(h)
+	}
+	if rp.err == nil && rp.disp != nil {
+		dispatch(rp.uis, rp.disp, e)
+	}
+}
+
+// check checks the cards dealt and the moves made in round h so far, the
+// results are checked at the end of the round.
+func (rp *replay) check(h *HandHistory) {
+	want := rp.want()
+	if want == nil {
+		if len(h.Cards) > 0 {
+			rp.diverged("round", 0, h.Round, nil)
+		}
+		return
+	}
+
+	if i := len(h.Cards) - 1; i >= 0 {
+		if i >= len(want.Cards) {
+			rp.diverged("card", i, h.Cards[i], nil)
+			return
+		}
+		if h.Cards[i] != want.Cards[i] {
+			rp.diverged("card", i, h.Cards[i], want.Cards[i])
+			return
+		}
+	}
+
+	if i := len(h.Moves) - 1; i >= 0 {
+		if i >= len(want.Moves) {
+			rp.diverged("move", i, h.Moves[i], nil)
+			return
+		}
+		if !equalMoves(h.Moves[i], want.Moves[i]) {
+			rp.diverged("move", i, h.Moves[i], want.Moves[i])
+		}
+	}
+}
+
+// done checks the results of finished round h.
+func (rp *replay) done(h *HandHistory) {
+	rp.check(h)
+	if rp.err != nil {
+		return
+	}
+
+	want := rp.want()
+	switch {
+	case h.Round != want.Round || !reflect.DeepEqual(h.Seats, want.Seats):
+		rp.diverged("round", 0, h.Round, want.Round)
+	case len(h.Cards) < len(want.Cards):
+		rp.diverged("card", len(h.Cards), nil, want.Cards[len(h.Cards)])
+	case len(h.Moves) < len(want.Moves):
+		rp.diverged("move", len(h.Moves), nil, want.Moves[len(h.Moves)])
+	}
+
+	for i, res := range h.Results {
+		if rp.err != nil {
+			return
+		}
+		if i >= len(want.Results) {
+			rp.diverged("result", i, res, nil)
+		} else if !equalResults(res, want.Results[i]) {
+			rp.diverged("result", i, res, want.Results[i])
+		}
+	}
+	if rp.err == nil && len(h.Results) < len(want.Results) {
+		rp.diverged("result", len(h.Results), nil, want.Results[len(h.Results)])
+	}
+	rp.round++
+}
+
+// diverged stops the replay with the first difference.
+func (rp *replay) diverged(what string, i int, got, want interface{}) {
+	if rp.err != nil {
+		return
+	}
+	round := rp.round + 1
+	if w := rp.want(); w != nil {
+		round = w.Round
+	}
+	rp.err = &ReplayError{Round: round, What: what, Index: i, Got: got, Want: want}
+	rp.cancel()
+}
+
+func equalMoves(a, b HistoryMove) bool {
+	return a.Seat == b.Seat && a.Hand == b.Hand && a.Phase == b.Phase &&
+		equalActions(a.Allowed, b.Allowed) && a.Action == b.Action &&
+		a.Amount.Equal(b.Amount) && a.Accept == b.Accept && a.Fortune.Equal(b.Fortune)
+}
+
+func equalActions(a, b []Action) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalResults(a, b HistoryResult) bool {
+	return a.Seat == b.Seat && a.Bet == b.Bet && a.Outcome == b.Outcome &&
+		len(a.Hand) == len(b.Hand) && (len(a.Hand) == 0 || reflect.DeepEqual(a.Hand, b.Hand)) &&
+		a.Amount.Equal(b.Amount) && a.Fortune.Equal(b.Fortune)
+}
+
+// replayShoe deals the recorded cards in order.
+type replayShoe []card.Card
+
+func (s *replayShoe) Draw() (card.Card, bool) {
+	if len(*s) == 0 {
+		return card.Card{}, false
+	}
+	c := (*s)[0]
+	*s = (*s)[1:]
+	return c, true
+}
+
+func (s *replayShoe) Shuffle(cards ...card.Card) {}
+
+// replayUI makes the recorded moves of a player, the events are reported
+// by the replay.
+type replayUI struct {
+	rp *replay
+}
+
+// move returns the next recorded move, when no move is left the player
+// leaves the table.
+func (u replayUI) move() Move {
+	m, _ := u.rp.next()
+	return m.Move()
+}
+
+func (u replayUI) Bet(*player.Fortune) decimal.Decimal { return u.move().Amount }
+func (u replayUI) NewGame(*player.Fortune) bool        { return u.move().Accept }
+func (u replayUI) NextAction([]Action) Action          { return u.move().Action }
+func (u replayUI) PerfectPairBet(*player.Fortune) decimal.Decimal {
+	return u.move().Amount
+}
+func (u replayUI) InsuranceBet(_ *player.Fortune, _ decimal.Decimal) decimal.Decimal {
+	return u.move().Amount
+}
+func (u replayUI) TakeEvenMoney() bool { return u.move().Accept }
+
+func (u replayUI) BetRejected(*BetError)                        {}
+func (u replayUI) Hand(DealerHand, Hand)                        {}
+func (u replayUI) DealerPeek(bool)                              {}
+func (u replayUI) DealerReveal(Hand)                            {}
+func (u replayUI) DealerCard(card.Card, Hand)                   {}
+func (u replayUI) SplitHand(Hand, Hand, decimal.Decimal)        {}
+func (u replayUI) DoubleHand(Hand, decimal.Decimal)             {}
+func (u replayUI) Outcome(Outcome, decimal.Decimal, Hand, Hand) {}
+func (u replayUI) NoActiveFortune(*player.Fortune) bool         { return false }
+func (u replayUI) Box(int)                                      {}
+func (u replayUI) NoFortune()                                   {}
+func (u replayUI) PerfectPair(PerfectPair, decimal.Decimal)     {}
+func (u replayUI) Insurance(bool, decimal.Decimal)              {}
@@ -0,0 +1,191 @@
+package blackjack
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+// recordRandom records a random session of rounds with rules r.
+func recordRandom(t *testing.T, r Rules, seed int64, rounds int) []*HandHistory {
+	t.Helper()
+	tbl := NewSeededTable(r, seed)
+	tbl.Sit(1, player.NewFortune(decimal.New(1000, 0)))
+	tbl.JoinBoxes(nil, player.NewFortune(decimal.New(1000, 0)), 3, 5)
+
+	var buf bytes.Buffer
+	hw := NewHistoryWriter(&buf, tbl)
+	playRandom(t, tbl, rand.New(rand.NewSource(seed)), rounds)
+	if err := hw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	hs, err := ReadHistory(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hs
+}
+
+type replayTestUI struct {
+	replayUI
+	moves int
+}
+
+func (ui *replayTestUI) Replay(m HistoryMove) { ui.moves++ }
+
+func TestReplay(t *testing.T) {
+	for _, rules := range AvailableRules {
+		t.Run(RulesName(rules), func(t *testing.T) {
+			hs := recordRandom(t, rules, 1, 25)
+			if len(hs) != 25 {
+				t.Fatalf("got %d rounds, want: 25", len(hs))
+			}
+
+			ui := &replayTestUI{}
+			if err := Replay(hs, nil, ui); err != nil {
+				t.Fatal(err)
+			}
+			if ui.moves == 0 {
+				t.Error("got no replayed moves")
+			}
+		})
+	}
+}
+
+func TestReplayDiverged(t *testing.T) {
+	cases := []struct {
+		name   string
+		change func(hs []*HandHistory)
+		round  int
+		what   string
+		index  int // -1 for any index
+	}{
+		{"Result", func(hs []*HandHistory) {
+			r := &hs[2].Results[0]
+			r.Amount = r.Amount.Add(decimal.New(1, 0))
+		}, 3, "result", 0},
+		{"Allowed", func(hs []*HandHistory) {
+			for i, m := range hs[2].Moves {
+				if m.Phase == PhaseAction {
+					hs[2].Moves[i].Allowed = nil
+					return
+				}
+			}
+		}, 3, "move", -1},
+		{"Fortune", func(hs []*HandHistory) {
+			m := &hs[0].Moves[0]
+			m.Fortune = m.Fortune.Add(decimal.New(1, 0))
+		}, 1, "move", -1},
+		{"MissingCards", func(hs []*HandHistory) {
+			hs[2].Cards = hs[2].Cards[:2]
+		}, 3, "card", 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			hs := recordRandom(t, LasVegasStrip, 2, 5)
+			c.change(hs)
+
+			err := Replay(hs, nil, nil)
+			re, ok := err.(*ReplayError)
+			if !ok {
+				t.Fatalf("got error %v, want: *ReplayError", err)
+			}
+			if re.Round != c.round || re.What != c.what || c.index >= 0 && re.Index != c.index {
+				t.Errorf("got %v, want: round %d %s %d", re, c.round, c.what, c.index)
+			}
+		})
+	}
+}
+
+func TestReplayInvalidMove(t *testing.T) {
+	hs := recordRandom(t, LasVegasStrip, 2, 3)
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(hs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first bet of the history line is made negative.
+	line := strings.Replace(buf.String(), `"amount":"`, `"amount":"-`, 1)
+	hs, err := ReadHistory(strings.NewReader(line))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Replay(hs, nil, nil)
+	re, ok := err.(*ReplayError)
+	if !ok {
+		t.Fatalf("got error %v, want: *ReplayError", err)
+	}
+	if re.Round != 1 || re.What != "move" || re.Index != 0 {
+		t.Errorf("got %v, want: round 1 move 0", re)
+	}
+	if me, ok := re.Got.(*MoveError); !ok || me.Err != ErrNegativeAmount {
+		t.Errorf("got %v, want: *MoveError with %v", re.Got, ErrNegativeAmount)
+	}
+}
+
+func TestReplayMalformed(t *testing.T) {
+	cases := []struct {
+		name   string
+		change func(hs []*HandHistory)
+	}{
+		{"NegativeSeat", func(hs []*HandHistory) { hs[0].Moves[0].Seat = -1 }},
+		{"SeatOutOfRange", func(hs []*HandHistory) { hs[1].Moves[0].Seat = MaxSeats }},
+		{"BoxOutOfRange", func(hs []*HandHistory) { hs[0].Boxes = [][]int{{1, MaxSeats + 2}} }},
+		{"BoxTwice", func(hs []*HandHistory) { hs[0].Boxes = [][]int{{1, 1}} }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			hs := recordRandom(t, LasVegasStrip, 2, 3)
+			c.change(hs)
+
+			err := Replay(hs, nil, nil)
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			if _, ok := err.(*ReplayError); ok {
+				t.Errorf("got divergence %v, want: error of the history", err)
+			}
+		})
+	}
+}
+
+// TestReplayCorpus replays the recorded sessions in testdata/replay.
+func TestReplayCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "replay", "*.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no recorded sessions")
+	}
+
+	for _, name := range files {
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			f, err := os.Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			hs, err := ReadHistory(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Replay(hs, nil, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -26,22 +26,59 @@ const (
 
 //go:generate stringer -type=SurrenderRule
 
+// SurrenderUpCard represents restrictions on surrender by the dealer up-card.
+type SurrenderUpCard int
+
+// Against which dealer up-cards is it possible to surrender?
+const (
+	SurrenderAnyUpCard SurrenderUpCard = iota
+	SurrenderNotAce
+	SurrenderNotAceTen
+)
+
+//go:generate stringer -type=SurrenderUpCard
+
+// PeekRule represents when the dealer checks the hole card for blackjack.
+type PeekRule int
+
+// Dealer peek options.
+const (
+	NoPeek PeekRule = iota
+	PeekAce
+	PeekAceTen
+)
+
+//go:generate stringer -type=PeekRule
+
 // Rules represent the game rules and mechanics.
 type Rules interface {
 	NumDecks() uint
 	DealerHitSoft17() bool
 	Surrender() SurrenderRule
-	CanSplit([]Hand) bool
+	SurrenderUpCard() SurrenderUpCard
+	SplitHands() int
+	SplitUnequalTens() bool
+	ResplitAces() bool
+	HitSplitAces() bool
+	DoubleSplitAces() bool
 	Double() DoubleRule
 	DoubleAfterSplit() bool
 	BlackjackAfterSplit() bool
 	NoHoleCard() bool
+	Peek() PeekRule
 	OriginalBetsOnly() bool
 	BlackjackRatio() decimal.Decimal
 	DealerWinsTie() bool
+	Payouts() Payouts
+	Bonuses() Bonuses
 
 	PerfectPair() bool
 	PerfectPairRatio() (mixed, same, perfect int)
+
+	Insurance() bool
+	EvenMoney() bool
+
+	Limits() Limits
 }
 
 // Game rules in different casino's.
@@ -54,36 +91,70 @@ var (
 var AvailableRules = []Rules{
 	HollandCasino,
 	TapTapBoom,
+	LasVegasStrip,
+	DowntownSingleDeck,
+	DowntownDoubleDeck,
+	AtlanticCity,
+	Reno,
+	European,
+	Macau,
+	OnlineCSM,
 }
 
 type holland struct{}
 
-func (holland) NumDecks() uint                  { return 6 }
-func (holland) DealerHitSoft17() bool           { return true }
-func (holland) Surrender() SurrenderRule        { return NoSurrender }
-func (holland) CanSplit([]Hand) bool            { return true }
-func (holland) Double() DoubleRule              { return DoubleOnly9_10_11 }
-func (holland) DoubleAfterSplit() bool          { return true }
-func (holland) BlackjackAfterSplit() bool       { return true }
-func (holland) NoHoleCard() bool                { return true }
-func (holland) OriginalBetsOnly() bool          { return false }
-func (holland) BlackjackRatio() decimal.Decimal { return decimal.New(15, -1) }
-func (holland) DealerWinsTie() bool             { return false }
-func (holland) PerfectPair() bool               { return true }
-func (holland) PerfectPairRatio() (m, s, p int) { return 6, 12, 25 }
+func (holland) String() string { return "Holland Casino" }
+
+func (holland) NumDecks() uint                   { return 6 }
+func (holland) DealerHitSoft17() bool            { return true }
+func (holland) Surrender() SurrenderRule         { return NoSurrender }
+func (holland) SurrenderUpCard() SurrenderUpCard { return SurrenderAnyUpCard }
+func (holland) SplitHands() int                  { return 0 }
+func (holland) SplitUnequalTens() bool           { return false }
+func (holland) ResplitAces() bool                { return false }
+func (holland) HitSplitAces() bool               { return false }
+func (holland) DoubleSplitAces() bool            { return false }
+func (holland) Double() DoubleRule               { return DoubleOnly9_10_11 }
+func (holland) DoubleAfterSplit() bool           { return true }
+func (holland) BlackjackAfterSplit() bool        { return true }
+func (holland) NoHoleCard() bool                 { return true }
+func (holland) Peek() PeekRule                   { return NoPeek }
+func (holland) OriginalBetsOnly() bool           { return false }
+func (holland) BlackjackRatio() decimal.Decimal  { return decimal.New(15, -1) }
+func (holland) DealerWinsTie() bool              { return false }
+func (holland) Payouts() Payouts                 { return Payouts{} }
+func (holland) Bonuses() Bonuses                 { return Bonuses{} }
+func (holland) PerfectPair() bool                { return true }
+func (holland) PerfectPairRatio() (m, s, p int)  { return 6, 12, 25 }
+func (holland) Insurance() bool                  { return false }
+func (holland) EvenMoney() bool                  { return false }
+func (holland) Limits() Limits                   { return Limits{} }
 
 type tapTapBoom struct{}
 
-func (tapTapBoom) NumDecks() uint                  { return 6 } // guess
-func (tapTapBoom) DealerHitSoft17() bool           { return true }
-func (tapTapBoom) Surrender() SurrenderRule        { return NoSurrender }
-func (tapTapBoom) CanSplit(h []Hand) bool          { return len(h) == 1 }
-func (tapTapBoom) Double() DoubleRule              { return DoubleAny }
-func (tapTapBoom) DoubleAfterSplit() bool          { return true }
-func (tapTapBoom) BlackjackAfterSplit() bool       { return true }
-func (tapTapBoom) NoHoleCard() bool                { return false }
-func (tapTapBoom) OriginalBetsOnly() bool          { return false }
-func (tapTapBoom) BlackjackRatio() decimal.Decimal { return decimal.New(15, -1) }
-func (tapTapBoom) DealerWinsTie() bool             { return false }
-func (tapTapBoom) PerfectPair() bool               { return false }
-func (tapTapBoom) PerfectPairRatio() (m, s, p int) { return }
+func (tapTapBoom) String() string { return "TapTapBoom" }
+
+func (tapTapBoom) NumDecks() uint                   { return 6 } // guess
+func (tapTapBoom) DealerHitSoft17() bool            { return true }
+func (tapTapBoom) Surrender() SurrenderRule         { return NoSurrender }
+func (tapTapBoom) SurrenderUpCard() SurrenderUpCard { return SurrenderAnyUpCard }
+func (tapTapBoom) SplitHands() int                  { return 2 }
+func (tapTapBoom) SplitUnequalTens() bool           { return false }
+func (tapTapBoom) ResplitAces() bool                { return false }
+func (tapTapBoom) HitSplitAces() bool               { return false }
+func (tapTapBoom) DoubleSplitAces() bool            { return false }
+func (tapTapBoom) Double() DoubleRule               { return DoubleAny }
+func (tapTapBoom) DoubleAfterSplit() bool           { return true }
+func (tapTapBoom) BlackjackAfterSplit() bool        { return true }
+func (tapTapBoom) NoHoleCard() bool                 { return false }
+func (tapTapBoom) Peek() PeekRule                   { return PeekAceTen }
+func (tapTapBoom) OriginalBetsOnly() bool           { return false }
+func (tapTapBoom) BlackjackRatio() decimal.Decimal  { return decimal.New(15, -1) }
+func (tapTapBoom) DealerWinsTie() bool              { return false }
+func (tapTapBoom) Payouts() Payouts                 { return Payouts{} }
+func (tapTapBoom) Bonuses() Bonuses                 { return Bonuses{} }
+func (tapTapBoom) PerfectPair() bool                { return false }
+func (tapTapBoom) PerfectPairRatio() (m, s, p int)  { return }
+func (tapTapBoom) Insurance() bool                  { return true }
+func (tapTapBoom) EvenMoney() bool                  { return true }
+func (tapTapBoom) Limits() Limits                   { return Limits{} }
@@ -4,109 +4,176 @@ import (
 	"testing"
 
 	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
 
 	"github.com/shopspring/decimal"
 )
 
 type testRules struct {
 	surrender SurrenderRule
+	upCard    SurrenderUpCard
+	peek      PeekRule
+	holeCard  bool
+	insurance bool
+	obo       bool
+
+	splitHands int
+	splitTens  bool
+	rsa        bool
+	hsa        bool
+	dsa        bool
+
+	payouts Payouts
+	bonuses Bonuses
+	limits  Limits
 }
 
-func (r testRules) NumDecks() uint                  { return 6 }
-func (r testRules) DealerHitSoft17() bool           { return true }
-func (r testRules) Surrender() SurrenderRule        { return r.surrender }
-func (r testRules) CanSplit([]Hand) bool            { return true }
-func (r testRules) Double() DoubleRule              { return DoubleAny }
-func (r testRules) DoubleAfterSplit() bool          { return true }
-func (r testRules) BlackjackAfterSplit() bool       { return true }
-func (r testRules) NoHoleCard() bool                { return true }
-func (r testRules) OriginalBetsOnly() bool          { return false }
-func (r testRules) BlackjackRatio() decimal.Decimal { return decimal.New(15, -1) }
-func (r testRules) DealerWinsTie() bool             { return true }
-func (r testRules) PerfectPair() bool               { return false }
-func (r testRules) PerfectPairRatio() (m, s, p int) { return }
+func (r testRules) NumDecks() uint                   { return 6 }
+func (r testRules) DealerHitSoft17() bool            { return true }
+func (r testRules) Surrender() SurrenderRule         { return r.surrender }
+func (r testRules) SurrenderUpCard() SurrenderUpCard { return r.upCard }
+func (r testRules) SplitHands() int                  { return r.splitHands }
+func (r testRules) SplitUnequalTens() bool           { return r.splitTens }
+func (r testRules) ResplitAces() bool                { return r.rsa }
+func (r testRules) HitSplitAces() bool               { return r.hsa }
+func (r testRules) DoubleSplitAces() bool            { return r.dsa }
+func (r testRules) Double() DoubleRule               { return DoubleAny }
+func (r testRules) DoubleAfterSplit() bool           { return true }
+func (r testRules) BlackjackAfterSplit() bool        { return true }
+func (r testRules) NoHoleCard() bool                 { return !r.holeCard }
+func (r testRules) Peek() PeekRule                   { return r.peek }
+func (r testRules) OriginalBetsOnly() bool           { return r.obo }
+func (r testRules) BlackjackRatio() decimal.Decimal  { return decimal.New(15, -1) }
+func (r testRules) DealerWinsTie() bool              { return true }
+func (r testRules) Payouts() Payouts                 { return r.payouts }
+func (r testRules) Bonuses() Bonuses                 { return r.bonuses }
+func (r testRules) PerfectPair() bool                { return false }
+func (r testRules) PerfectPairRatio() (m, s, p int)  { return }
+func (r testRules) Insurance() bool                  { return r.insurance }
+func (r testRules) EvenMoney() bool                  { return r.insurance }
+func (r testRules) Limits() Limits                   { return r.limits }
 
 func TestDoubleBlackjackAfterSplit(t *testing.T) {
 	rules := testRules{surrender: EarlySurrender}
-	testPlay(t, 38, rules, 10, 5, []event{
+	testPlay(t, 1990, rules, 10, 5, []event{
 		hand{
-			dealer: Hand{card.Diamond(card.Nine)},
-			player: Hand{card.Diamond(card.Ten), card.Diamond(card.Ten)},
+			dealer: Hand{card.Spade(card.Four)},
+			player: Hand{card.Heart(card.Queen), card.Heart(card.Queen)},
 		},
 		nextAction{[]Action{Surrender, Continue}, Continue},
 		hand{
-			dealer: Hand{card.Diamond(card.Nine)},
-			player: Hand{card.Diamond(card.Ten), card.Diamond(card.Ten)},
+			dealer: Hand{card.Spade(card.Four)},
+			player: Hand{card.Heart(card.Queen), card.Heart(card.Queen)},
 		},
 		nextAction{[]Action{Hit, Stand, Split, Double}, Split},
 		splitHand{
-			left:  Hand{card.Diamond(card.Ten), card.Spade(card.Four)},
-			right: Hand{card.Diamond(card.Ten), card.Spade(card.Ace)},
+			left:  Hand{card.Heart(card.Queen), card.Club(card.Ten)},
+			right: Hand{card.Heart(card.Queen), card.Club(card.Ace)},
 		},
 		hand{
-			dealer: Hand{card.Diamond(card.Nine)},
-			player: Hand{card.Diamond(card.Ten), card.Spade(card.Four)},
+			dealer: Hand{card.Spade(card.Four)},
+			player: Hand{card.Heart(card.Queen), card.Club(card.Ten)},
 		},
 		nextAction{[]Action{Hit, Stand, Double}, Double},
 		doubleHand{
 			hand: Hand{
-				card.Diamond(card.Ten),
-				card.Spade(card.Four),
+				card.Heart(card.Queen),
+				card.Club(card.Ten),
 				card.Diamond(card.Nine),
 			},
 			withdrawn: decimal.New(10, 0),
 		},
 		dealerCard{
-			card: card.Diamond(card.Ten),
-			hand: Hand{card.Diamond(card.Nine), card.Diamond(card.Ten)},
+			card: card.Spade(card.Four),
+			hand: Hand{card.Spade(card.Four), card.Spade(card.Four)},
+		},
+		dealerCard{
+			card: card.Spade(card.Six),
+			hand: Hand{
+				card.Spade(card.Four),
+				card.Spade(card.Four),
+				card.Spade(card.Six),
+			},
+		},
+		dealerCard{
+			card: card.Heart(card.Queen),
+			hand: Hand{
+				card.Spade(card.Four),
+				card.Spade(card.Four),
+				card.Spade(card.Six),
+				card.Heart(card.Queen),
+			},
 		},
 		outcome{
 			outcome: Bust,
 			amount:  decimal.New(-20, 0),
-			dealer:  Hand{card.Diamond(card.Nine), card.Diamond(card.Ten)},
-			player: Hand{
-				card.Diamond(card.Ten),
+			dealer: Hand{
+				card.Spade(card.Four),
 				card.Spade(card.Four),
+				card.Spade(card.Six),
+				card.Heart(card.Queen),
+			},
+			player: Hand{
+				card.Heart(card.Queen),
+				card.Club(card.Ten),
 				card.Diamond(card.Nine),
 			},
 		},
 		outcome{
 			outcome: Blackjack,
 			amount:  decimal.New(25, 0),
-			dealer:  Hand{card.Diamond(card.Nine), card.Diamond(card.Ten)},
-			player:  Hand{card.Diamond(card.Ten), card.Spade(card.Ace)},
+			dealer: Hand{
+				card.Spade(card.Four),
+				card.Spade(card.Four),
+				card.Spade(card.Six),
+				card.Heart(card.Queen),
+			},
+			player: Hand{card.Heart(card.Queen), card.Club(card.Ace)},
 		},
 	})
 }
 
 func TestDealerWinsTie(t *testing.T) {
 	rules := testRules{surrender: NoSurrender}
-	testPlay(t, 10, rules, 10, 0, []event{
+	testPlay(t, 47, rules, 10, 0, []event{
 		hand{
-			dealer: Hand{card.Heart(card.King)},
-			player: Hand{card.Heart(card.Three), card.Club(card.Queen)},
+			dealer: Hand{card.Club(card.Seven)},
+			player: Hand{card.Heart(card.Five), card.Diamond(card.Six)},
 		},
 		nextAction{[]Action{Hit, Stand, Double}, Hit},
 		hand{
-			dealer: Hand{card.Heart(card.King)},
+			dealer: Hand{card.Club(card.Seven)},
 			player: Hand{
-				card.Heart(card.Three),
-				card.Club(card.Queen),
-				card.Heart(card.Eight),
+				card.Heart(card.Five),
+				card.Diamond(card.Six),
+				card.Club(card.Eight),
 			},
 		},
+		nextAction{[]Action{Hit, Stand}, Stand},
+		dealerCard{
+			card: card.Spade(card.Eight),
+			hand: Hand{card.Club(card.Seven), card.Spade(card.Eight)},
+		},
 		dealerCard{
-			card: card.Spade(card.Ace),
-			hand: Hand{card.Heart(card.King), card.Spade(card.Ace)},
+			card: card.Spade(card.Four),
+			hand: Hand{
+				card.Club(card.Seven),
+				card.Spade(card.Eight),
+				card.Spade(card.Four),
+			},
 		},
 		outcome{
 			outcome: Lost,
 			amount:  decimal.New(-10, 0),
-			dealer:  Hand{card.Heart(card.King), card.Spade(card.Ace)},
+			dealer: Hand{
+				card.Club(card.Seven),
+				card.Spade(card.Eight),
+				card.Spade(card.Four),
+			},
 			player: Hand{
-				card.Heart(card.Three),
-				card.Club(card.Queen),
-				card.Heart(card.Eight),
+				card.Heart(card.Five),
+				card.Diamond(card.Six),
+				card.Club(card.Eight),
 			},
 		},
 	})
@@ -114,67 +181,266 @@ func TestDealerWinsTie(t *testing.T) {
 
 func TestDealerHitSoft17(t *testing.T) {
 	rules := testRules{surrender: NoSurrender}
-	testPlay(t, 20, rules, 10, 0, []event{
+	testPlay(t, 65, rules, 10, 0, []event{
 		hand{
-			dealer: Hand{card.Heart(card.Ten)},
-			player: Hand{card.Club(card.Three), card.Heart(card.Ace)},
+			dealer: Hand{card.Spade(card.Ace)},
+			player: Hand{card.Heart(card.Queen), card.Spade(card.Ten)},
 		},
 		nextAction{[]Action{Hit, Stand, Double}, Stand},
 		dealerCard{
-			card: card.Spade(card.Seven),
-			hand: Hand{card.Heart(card.Ten), card.Spade(card.Seven)},
+			card: card.Club(card.Six),
+			hand: Hand{card.Spade(card.Ace), card.Club(card.Six)},
 		},
 		dealerCard{
-			card: card.Club(card.King),
+			card: card.Club(card.Two),
 			hand: Hand{
-				card.Heart(card.Ten),
-				card.Spade(card.Seven),
-				card.Club(card.King),
+				card.Spade(card.Ace),
+				card.Club(card.Six),
+				card.Club(card.Two),
 			},
 		},
 		outcome{
 			outcome: Won,
 			amount:  decimal.New(20, 0),
 			dealer: Hand{
-				card.Heart(card.Ten),
-				card.Spade(card.Seven),
-				card.Club(card.King),
+				card.Spade(card.Ace),
+				card.Club(card.Six),
+				card.Club(card.Two),
 			},
-			player: Hand{card.Club(card.Three), card.Heart(card.Ace)},
+			player: Hand{card.Heart(card.Queen), card.Spade(card.Ten)},
+		},
+	})
+}
+
+func TestDealerStandHard17(t *testing.T) {
+	rules := testRules{surrender: NoSurrender}
+	testPlay(t, 34, rules, 10, 0, []event{
+		hand{
+			dealer: Hand{card.Spade(card.Ten)},
+			player: Hand{card.Club(card.Three), card.Heart(card.Queen)},
+		},
+		nextAction{[]Action{Hit, Stand, Double}, Stand},
+		dealerCard{
+			card: card.Diamond(card.Seven),
+			hand: Hand{card.Spade(card.Ten), card.Diamond(card.Seven)},
+		},
+		outcome{
+			outcome: Lost,
+			amount:  decimal.New(-10, 0),
+			dealer:  Hand{card.Spade(card.Ten), card.Diamond(card.Seven)},
+			player:  Hand{card.Club(card.Three), card.Heart(card.Queen)},
 		},
 	})
 }
 
 func TestEarlySurrendered(t *testing.T) {
 	rules := testRules{surrender: EarlySurrender}
-	testPlay(t, 5, rules, 10, 0, []event{
+	testPlay(t, 1, rules, 10, 0, []event{
 		hand{
-			dealer: Hand{card.Spade(card.King)},
-			player: Hand{card.Diamond(card.Five), card.Diamond(card.Jack)},
+			dealer: Hand{card.Heart(card.Ace)},
+			player: Hand{card.Heart(card.Jack), card.Heart(card.Queen)},
 		},
 		nextAction{[]Action{Surrender, Continue}, Surrender},
 		outcome{
 			outcome: Surrendered,
 			amount:  decimal.New(5, 0),
-			dealer:  Hand{card.Spade(card.King)},
-			player:  Hand{card.Diamond(card.Five), card.Diamond(card.Jack)},
+			dealer:  Hand{card.Heart(card.Ace)},
+			player:  Hand{card.Heart(card.Jack), card.Heart(card.Queen)},
 		},
 	})
 }
 
 func TestLateSurrendered(t *testing.T) {
+	rules := testRules{surrender: LateSurrender, holeCard: true, peek: PeekAceTen}
+	testPlay(t, 12, rules, 10, 0, []event{
+		dealerPeek{false},
+		hand{
+			dealer: Hand{card.Club(card.Jack)},
+			player: Hand{card.Spade(card.Four), card.Spade(card.Queen)},
+		},
+		nextAction{[]Action{Hit, Stand, Double, Surrender}, Surrender},
+		outcome{
+			outcome: Surrendered,
+			amount:  decimal.New(5, 0),
+			dealer:  Hand{card.Club(card.Jack), card.Club(card.Nine)},
+			player:  Hand{card.Spade(card.Four), card.Spade(card.Queen)},
+		},
+	})
+}
+
+func TestLateSurrenderWithoutPeek(t *testing.T) {
+	rules := testRules{surrender: LateSurrender, holeCard: true, peek: NoPeek}
+	testPlay(t, 12, rules, 10, 0, []event{
+		hand{
+			dealer: Hand{card.Club(card.Jack)},
+			player: Hand{card.Spade(card.Four), card.Spade(card.Queen)},
+		},
+		nextAction{[]Action{Hit, Stand, Double}, Stand},
+		dealerReveal{
+			hand: Hand{card.Club(card.Jack), card.Club(card.Nine)},
+		},
+		outcome{
+			outcome: Lost,
+			amount:  decimal.New(-10, 0),
+			dealer:  Hand{card.Club(card.Jack), card.Club(card.Nine)},
+			player:  Hand{card.Spade(card.Four), card.Spade(card.Queen)},
+		},
+	})
+}
+
+func TestLateSurrenderNoHoleCard(t *testing.T) {
 	rules := testRules{surrender: LateSurrender}
-	testPlay(t, 164, rules, 10, 0, []event{
+	testPlay(t, 2, rules, 10, 0, []event{
 		hand{
-			dealer: Hand{card.Diamond(card.Ten)},
-			player: Hand{card.Heart(card.Six), card.Club(card.Jack)},
+			dealer: Hand{card.Heart(card.Two)},
+			player: Hand{card.Diamond(card.Six), card.Spade(card.Two)},
 		},
-		nextAction{[]Action{Surrender, Continue}, Surrender},
+		nextAction{[]Action{Hit, Stand, Double, Surrender}, Surrender},
 		outcome{
 			outcome: Surrendered,
 			amount:  decimal.New(5, 0),
-			dealer:  Hand{card.Diamond(card.Ten)},
-			player:  Hand{card.Heart(card.Six), card.Club(card.Jack)},
+			dealer:  Hand{card.Heart(card.Two)},
+			player:  Hand{card.Diamond(card.Six), card.Spade(card.Two)},
 		},
 	})
 }
+
+func TestEarlySurrenderUpCard(t *testing.T) {
+	dealer := Hand{card.Spade(card.Ace), card.Spade(card.Two)}
+	player := Hand{card.Heart(card.Eight), card.Heart(card.King)}
+	final := append(dealer[:2:2], card.Diamond(card.Nine), card.Diamond(card.Jack))
+
+	play := []event{
+		dealerPeek{false},
+		hand{dealer[:1], player},
+		nextAction{[]Action{Hit, Stand, Double}, Stand},
+		dealerReveal{dealer},
+		dealerCard{final[2], final[:3]},
+		dealerCard{final[3], final},
+		outcome{Won, decimal.New(20, 0), final, player},
+	}
+
+	cases := []struct {
+		upCard SurrenderUpCard
+		want   []event
+	}{
+		{SurrenderAnyUpCard, append([]event{
+			hand{dealer[:1], player},
+			nextAction{[]Action{Surrender, Continue}, Continue},
+		}, play...)},
+		{SurrenderNotAce, play},
+		{SurrenderNotAceTen, play},
+	}
+
+	for _, c := range cases {
+		t.Run(c.upCard.String(), func(t *testing.T) {
+			rules := testRules{
+				surrender: EarlySurrender,
+				upCard:    c.upCard,
+				holeCard:  true,
+				peek:      PeekAceTen,
+			}
+			testPlay(t, 120, rules, 10, 0, c.want)
+		})
+	}
+}
+
+// settleUI is a UI that only accepts outcomes.
+type settleUI struct{ UI }
+
+func (settleUI) Outcome(Outcome, decimal.Decimal, Hand, Hand) {}
+
+func TestNoHoleCardDealerBlackjack(t *testing.T) {
+	ten := decimal.New(10, 0)
+	twenty := decimal.New(20, 0)
+	lost := makeHand(card.Ten, card.Nine)
+	bust := makeHand(card.Ten, card.Six, card.Nine)
+
+	// Refunded amount after a dealer blackjack, bets of 10 are placed.
+	cases := []struct {
+		name string
+		bets []*bet
+		enhc int64
+		obo  int64
+	}{
+		{"Single", []*bet{
+			{hand: lost, amount: ten},
+		}, 0, 0},
+		{"Doubled", []*bet{
+			{hand: lost, amount: twenty, doubled: true},
+		}, 0, 10},
+		{"DoubledBust", []*bet{
+			{hand: bust, amount: twenty, doubled: true},
+		}, 0, 0},
+		{"Split", []*bet{
+			{hand: lost, amount: ten},
+			{hand: lost, amount: ten},
+		}, 0, 10},
+		{"SplitBust", []*bet{
+			{hand: lost, amount: ten},
+			{hand: bust, amount: ten},
+		}, 0, 0},
+		{"SplitDoubled", []*bet{
+			{hand: lost, amount: twenty, doubled: true},
+			{hand: lost, amount: ten},
+		}, 0, 20},
+		{"SplitDoubledBoth", []*bet{
+			{hand: lost, amount: twenty, doubled: true},
+			{hand: lost, amount: twenty, doubled: true},
+		}, 0, 30},
+		{"SplitBlackjack", []*bet{
+			{hand: lost, amount: ten},
+			{hand: makeHand(card.Ace, card.King), amount: ten, blackjack: true},
+		}, 10, 10},
+	}
+
+	for _, c := range cases {
+		for _, obo := range []bool{false, true} {
+			want := c.enhc
+			name := c.name + "/ENHC"
+			if obo {
+				want = c.obo
+				name = c.name + "/OBO"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				bets := make([]*bet, len(c.bets))
+				for i, b := range c.bets {
+					cp := *b
+					bets[i] = &cp
+				}
+
+				f := player.NewFortune(decimal.Zero)
+				g := &game{
+					table: &table{
+						rules:  testRules{obo: obo},
+						dealer: makeHand(card.Ace, card.Queen),
+					},
+					ui:      settleUI{},
+					fortune: f,
+					bets:    bets,
+					sum:     &Summary{},
+				}
+				g.dealerBlackjack()
+
+				if !f.Active().Equal(decimal.New(want, 0)) {
+					t.Errorf("got refund %v, want: %v", f.Active(), want)
+				}
+				var outcomes []Outcome
+				for _, e := range g.events {
+					if e, ok := e.(OutcomeEvent); ok {
+						outcomes = append(outcomes, e.Outcome)
+					}
+				}
+				if len(outcomes) != len(bets) {
+					t.Fatalf("got outcomes %v, want one for each of %d bets", outcomes, len(bets))
+				}
+				for i, b := range bets {
+					if b.hand.Value().Bust && outcomes[i] != Bust {
+						t.Errorf("bet %d: got outcome %v for a bust hand, want: %v", i, outcomes[i], Bust)
+					}
+				}
+			})
+		}
+	}
+}
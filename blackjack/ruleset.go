@@ -0,0 +1,374 @@
+package blackjack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleSet is a configurable set of game rules. Every rule is a field so
+// rule sets can be loaded from configuration files.
+type RuleSet struct {
+	Name        string `json:"name" toml:"name" yaml:"name"`
+	Description string `json:"description,omitempty" toml:"description,omitempty" yaml:"description,omitempty"`
+
+	Decks         uint            `json:"decks" toml:"decks" yaml:"decks"`
+	HitSoft17     bool            `json:"hitSoft17" toml:"hitSoft17" yaml:"hitSoft17"`
+	SurrenderRule SurrenderRule   `json:"surrender" toml:"surrender" yaml:"surrender"`
+	SurrenderUp   SurrenderUpCard `json:"surrenderUpCard" toml:"surrenderUpCard" yaml:"surrenderUpCard"`
+	MaxHands      int             `json:"splitHands" toml:"splitHands" yaml:"splitHands"`
+	UnequalTens   bool            `json:"splitUnequalTens" toml:"splitUnequalTens" yaml:"splitUnequalTens"`
+	RSA           bool            `json:"resplitAces" toml:"resplitAces" yaml:"resplitAces"`
+	HSA           bool            `json:"hitSplitAces" toml:"hitSplitAces" yaml:"hitSplitAces"`
+	DSA           bool            `json:"doubleSplitAces" toml:"doubleSplitAces" yaml:"doubleSplitAces"`
+	DoubleRule    DoubleRule      `json:"double" toml:"double" yaml:"double"`
+	DAS           bool            `json:"doubleAfterSplit" toml:"doubleAfterSplit" yaml:"doubleAfterSplit"`
+	BJAfterSplit  bool            `json:"blackjackAfterSplit" toml:"blackjackAfterSplit" yaml:"blackjackAfterSplit"`
+	ENHC          bool            `json:"noHoleCard" toml:"noHoleCard" yaml:"noHoleCard"`
+	PeekRule      PeekRule        `json:"peek" toml:"peek" yaml:"peek"`
+	OBO           bool            `json:"originalBetsOnly" toml:"originalBetsOnly" yaml:"originalBetsOnly"`
+	BJRatio       decimal.Decimal `json:"blackjackRatio" toml:"blackjackRatio" yaml:"blackjackRatio"`
+	TiesLose      bool            `json:"dealerWinsTie" toml:"dealerWinsTie" yaml:"dealerWinsTie"`
+	PayoutTable   Payouts         `json:"payouts" toml:"payouts" yaml:"payouts"`
+	BonusHands    Bonuses         `json:"bonuses" toml:"bonuses" yaml:"bonuses"`
+
+	PerfectPairs    bool   `json:"perfectPair" toml:"perfectPair" yaml:"perfectPair"`
+	PerfectPairPays [3]int `json:"perfectPairRatio" toml:"perfectPairRatio" yaml:"perfectPairRatio"`
+
+	InsuranceBets bool `json:"insurance" toml:"insurance" yaml:"insurance"`
+	EvenMoneyBets bool `json:"evenMoney" toml:"evenMoney" yaml:"evenMoney"`
+
+	TableLimits Limits `json:"limits" toml:"limits" yaml:"limits"`
+}
+
+// NewRuleSet returns a rule set with the same rules as r.
+func NewRuleSet(name string, r Rules) *RuleSet {
+	m, s, p := r.PerfectPairRatio()
+	return &RuleSet{
+		Name:            name,
+		Decks:           r.NumDecks(),
+		HitSoft17:       r.DealerHitSoft17(),
+		SurrenderRule:   r.Surrender(),
+		SurrenderUp:     r.SurrenderUpCard(),
+		MaxHands:        r.SplitHands(),
+		UnequalTens:     r.SplitUnequalTens(),
+		RSA:             r.ResplitAces(),
+		HSA:             r.HitSplitAces(),
+		DSA:             r.DoubleSplitAces(),
+		DoubleRule:      r.Double(),
+		DAS:             r.DoubleAfterSplit(),
+		BJAfterSplit:    r.BlackjackAfterSplit(),
+		ENHC:            r.NoHoleCard(),
+		PeekRule:        r.Peek(),
+		OBO:             r.OriginalBetsOnly(),
+		BJRatio:         r.BlackjackRatio(),
+		TiesLose:        r.DealerWinsTie(),
+		PayoutTable:     r.Payouts(),
+		BonusHands:      r.Bonuses(),
+		PerfectPairs:    r.PerfectPair(),
+		PerfectPairPays: [3]int{m, s, p},
+		InsuranceBets:   r.Insurance(),
+		EvenMoneyBets:   r.EvenMoney(),
+		TableLimits:     r.Limits(),
+	}
+}
+
+func (rs *RuleSet) String() string { return rs.Name }
+
+// NumDecks implements Rules.
+func (rs *RuleSet) NumDecks() uint { return rs.Decks }
+
+// DealerHitSoft17 implements Rules.
+func (rs *RuleSet) DealerHitSoft17() bool { return rs.HitSoft17 }
+
+// Surrender implements Rules.
+func (rs *RuleSet) Surrender() SurrenderRule { return rs.SurrenderRule }
+
+// SurrenderUpCard implements Rules.
+func (rs *RuleSet) SurrenderUpCard() SurrenderUpCard { return rs.SurrenderUp }
+
+// SplitHands implements Rules.
+func (rs *RuleSet) SplitHands() int { return rs.MaxHands }
+
+// SplitUnequalTens implements Rules.
+func (rs *RuleSet) SplitUnequalTens() bool { return rs.UnequalTens }
+
+// ResplitAces implements Rules.
+func (rs *RuleSet) ResplitAces() bool { return rs.RSA }
+
+// HitSplitAces implements Rules.
+func (rs *RuleSet) HitSplitAces() bool { return rs.HSA }
+
+// DoubleSplitAces implements Rules.
+func (rs *RuleSet) DoubleSplitAces() bool { return rs.DSA }
+
+// Double implements Rules.
+func (rs *RuleSet) Double() DoubleRule { return rs.DoubleRule }
+
+// DoubleAfterSplit implements Rules.
+func (rs *RuleSet) DoubleAfterSplit() bool { return rs.DAS }
+
+// BlackjackAfterSplit implements Rules.
+func (rs *RuleSet) BlackjackAfterSplit() bool { return rs.BJAfterSplit }
+
+// NoHoleCard implements Rules.
+func (rs *RuleSet) NoHoleCard() bool { return rs.ENHC }
+
+// Peek implements Rules.
+func (rs *RuleSet) Peek() PeekRule { return rs.PeekRule }
+
+// OriginalBetsOnly implements Rules.
+func (rs *RuleSet) OriginalBetsOnly() bool { return rs.OBO }
+
+// BlackjackRatio implements Rules.
+func (rs *RuleSet) BlackjackRatio() decimal.Decimal { return rs.BJRatio }
+
+// DealerWinsTie implements Rules.
+func (rs *RuleSet) DealerWinsTie() bool { return rs.TiesLose }
+
+// Payouts implements Rules.
+func (rs *RuleSet) Payouts() Payouts { return rs.PayoutTable }
+
+// Bonuses implements Rules.
+func (rs *RuleSet) Bonuses() Bonuses { return rs.BonusHands }
+
+// PerfectPair implements Rules.
+func (rs *RuleSet) PerfectPair() bool { return rs.PerfectPairs }
+
+// PerfectPairRatio implements Rules.
+func (rs *RuleSet) PerfectPairRatio() (mixed, same, perfect int) {
+	p := rs.PerfectPairPays
+	return p[0], p[1], p[2]
+}
+
+// Insurance implements Rules.
+func (rs *RuleSet) Insurance() bool { return rs.InsuranceBets }
+
+// EvenMoney implements Rules.
+func (rs *RuleSet) EvenMoney() bool { return rs.EvenMoneyBets }
+
+// Limits implements Rules.
+func (rs *RuleSet) Limits() Limits { return rs.TableLimits }
+
+// RuleSetError describes why a rule set is invalid.
+type RuleSetError struct {
+	Name     string
+	Problems []string
+}
+
+func (e *RuleSetError) Error() string {
+	return fmt.Sprintf("blackjack: invalid rule set %q: %s",
+		e.Name, strings.Join(e.Problems, "; "))
+}
+
+// Validate checks rule set rs for impossible combinations of rules.
+// The returned error is a *RuleSetError.
+func (rs *RuleSet) Validate() error {
+	var p []string
+	if rs.Decks == 0 {
+		p = append(p, "no decks")
+	}
+	if rs.BJRatio.Cmp(decimal.Zero) <= 0 {
+		p = append(p, "blackjack ratio must be positive")
+	}
+	if rs.MaxHands < 0 || rs.MaxHands == 1 {
+		p = append(p, fmt.Sprintf("invalid number of split hands: %d", rs.MaxHands))
+	}
+	if rs.RSA && rs.MaxHands == 2 {
+		p = append(p, "resplitting aces needs more than 2 split hands")
+	}
+	if rs.DSA && !rs.DAS {
+		p = append(p, "doubling split aces needs double after split")
+	}
+	if rs.SurrenderRule == NoSurrender && rs.SurrenderUp != SurrenderAnyUpCard {
+		p = append(p, "surrender up-card restriction without surrender")
+	}
+	if rs.ENHC && rs.PeekRule != NoPeek {
+		p = append(p, "dealer can't peek without a hole card")
+	}
+	if rs.EvenMoneyBets && !rs.InsuranceBets {
+		p = append(p, "even money needs insurance")
+	}
+	if rs.PerfectPairs && rs.PerfectPairPays == [3]int{} {
+		p = append(p, "perfect pair without payouts")
+	}
+	if !rs.PayoutTable.valid() {
+		p = append(p, "invalid payout table")
+	}
+	if !rs.BonusHands.valid() {
+		p = append(p, "invalid bonus hands")
+	}
+	if l := rs.TableLimits; l.Min.Sign() < 0 || l.Max.Sign() < 0 || l.Unit.Sign() < 0 ||
+		l.SideMin.Sign() < 0 || l.SideMax.Sign() < 0 || l.MaxPayout.Sign() < 0 {
+		p = append(p, "negative table limit")
+	} else if l.Max.Sign() > 0 && l.Min.Cmp(l.Max) > 0 ||
+		l.SideMax.Sign() > 0 && l.SideMin.Cmp(l.SideMax) > 0 {
+		p = append(p, "table minimum above maximum")
+	}
+	if !validOption(int(rs.SurrenderRule), len(_SurrenderRule_index)-1) ||
+		!validOption(int(rs.SurrenderUp), len(_SurrenderUpCard_index)-1) ||
+		!validOption(int(rs.DoubleRule), len(_DoubleRule_index)-1) ||
+		!validOption(int(rs.PeekRule), len(_PeekRule_index)-1) ||
+		!validOption(int(rs.TableLimits.Rounding), len(_Rounding_index)-1) {
+		p = append(p, "unknown rule option")
+	}
+
+	if len(p) > 0 {
+		return &RuleSetError{Name: rs.Name, Problems: p}
+	}
+	return nil
+}
+
+func validOption(i, n int) bool { return i >= 0 && i < n }
+
+// Rule set file formats.
+const (
+	JSON = "json"
+	TOML = "toml"
+	YAML = "yaml"
+)
+
+// DecodeRuleSet reads a rule set in a format from r and validates it.
+func DecodeRuleSet(r io.Reader, format string) (*RuleSet, error) {
+	rs := new(RuleSet)
+	var err error
+
+	switch format {
+	case JSON:
+		d := json.NewDecoder(r)
+		d.DisallowUnknownFields()
+		err = d.Decode(rs)
+	case TOML:
+		var md toml.MetaData
+		md, err = toml.NewDecoder(r).Decode(rs)
+		if err == nil && len(md.Undecoded()) > 0 {
+			err = fmt.Errorf("unknown rules: %v", md.Undecoded())
+		}
+	case YAML:
+		d := yaml.NewDecoder(r)
+		d.KnownFields(true)
+		err = d.Decode(rs)
+	default:
+		return nil, fmt.Errorf("blackjack: unknown rule set format %q", format)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("blackjack: decode rule set: %v", err)
+	}
+
+	if err := rs.Validate(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// LoadRuleSet loads a rule set from a file, the format is determined by
+// its extension. A rule set without a name is named after the file.
+func LoadRuleSet(name string) (*RuleSet, error) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+	if format == "yml" {
+		format = YAML
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rs, err := DecodeRuleSet(f, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if rs.Name == "" {
+		rs.Name = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	}
+	return rs, nil
+}
+
+// LoadAvailableRules loads rule sets from files and adds them to
+// AvailableRules, like the presets they can't be changed.
+func LoadAvailableRules(names ...string) error {
+	for _, name := range names {
+		rs, err := LoadRuleSet(name)
+		if err != nil {
+			return err
+		}
+		AvailableRules = append(AvailableRules, preset{rs})
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r DoubleRule) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *DoubleRule) UnmarshalText(text []byte) error {
+	i, err := parseOption("double rule", string(text), len(_DoubleRule_index)-1,
+		func(i int) string { return DoubleRule(i).String() })
+	*r = DoubleRule(i)
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r SurrenderRule) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *SurrenderRule) UnmarshalText(text []byte) error {
+	i, err := parseOption("surrender rule", string(text), len(_SurrenderRule_index)-1,
+		func(i int) string { return SurrenderRule(i).String() })
+	*r = SurrenderRule(i)
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r SurrenderUpCard) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *SurrenderUpCard) UnmarshalText(text []byte) error {
+	i, err := parseOption("surrender up-card rule", string(text), len(_SurrenderUpCard_index)-1,
+		func(i int) string { return SurrenderUpCard(i).String() })
+	*r = SurrenderUpCard(i)
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r PeekRule) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *PeekRule) UnmarshalText(text []byte) error {
+	i, err := parseOption("peek rule", string(text), len(_PeekRule_index)-1,
+		func(i int) string { return PeekRule(i).String() })
+	*r = PeekRule(i)
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r Rounding) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *Rounding) UnmarshalText(text []byte) error {
+	i, err := parseOption("rounding", string(text), len(_Rounding_index)-1,
+		func(i int) string { return Rounding(i).String() })
+	*r = Rounding(i)
+	return err
+}
+
+// parseOption returns the option out of n options whose name matches s,
+// ignoring case.
+func parseOption(kind, s string, n int, name func(int) string) (int, error) {
+	for i := 0; i < n; i++ {
+		if strings.EqualFold(name(i), s) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown %s: %q", kind, s)
+}
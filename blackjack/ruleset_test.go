@@ -0,0 +1,224 @@
+package blackjack
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+var testRuleSet = &RuleSet{
+	Name:          "Test",
+	Decks:         8,
+	HitSoft17:     true,
+	SurrenderRule: LateSurrender,
+	SurrenderUp:   SurrenderNotAce,
+	MaxHands:      4,
+	RSA:           true,
+	DoubleRule:    DoubleOnly10_11,
+	DAS:           true,
+	PeekRule:      PeekAceTen,
+	BJRatio:       decimal.New(12, -1),
+	InsuranceBets: true,
+	EvenMoneyBets: true,
+	TableLimits:   Limits{Rounding: RoundHalfUp},
+}
+
+var testRuleSetFiles = map[string]string{
+	JSON: `{
+	"name": "Test",
+	"decks": 8,
+	"hitSoft17": true,
+	"surrender": "LateSurrender",
+	"surrenderUpCard": "SurrenderNotAce",
+	"splitHands": 4,
+	"resplitAces": true,
+	"double": "DoubleOnly10_11",
+	"doubleAfterSplit": true,
+	"peek": "peekaceten",
+	"blackjackRatio": 1.2,
+	"insurance": true,
+	"evenMoney": true,
+	"limits": {"rounding": "RoundHalfUp"}
+}`,
+	TOML: `name = "Test"
+decks = 8
+hitSoft17 = true
+surrender = "LateSurrender"
+surrenderUpCard = "SurrenderNotAce"
+splitHands = 4
+resplitAces = true
+double = "DoubleOnly10_11"
+doubleAfterSplit = true
+peek = "PeekAceTen"
+blackjackRatio = "1.2"
+insurance = true
+evenMoney = true
+
+[limits]
+rounding = "roundhalfup"
+`,
+	YAML: `name: Test
+decks: 8
+hitSoft17: true
+surrender: LateSurrender
+surrenderUpCard: SurrenderNotAce
+splitHands: 4
+resplitAces: true
+double: DoubleOnly10_11
+doubleAfterSplit: true
+peek: PeekAceTen
+blackjackRatio: 1.2
+insurance: true
+evenMoney: true
+limits:
+  rounding: RoundHalfUp
+`,
+}
+
+func TestDecodeRuleSet(t *testing.T) {
+	for format, file := range testRuleSetFiles {
+		t.Run(format, func(t *testing.T) {
+			rs, err := DecodeRuleSet(strings.NewReader(file), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !rs.BJRatio.Equal(testRuleSet.BJRatio) {
+				t.Errorf("got blackjack ratio %v, want: %v", rs.BJRatio, testRuleSet.BJRatio)
+			}
+			got, want := *rs, *testRuleSet
+			got.BJRatio, want.BJRatio = decimal.Zero, decimal.Zero
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got: %+v\nwant: %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeRuleSetNumbers(t *testing.T) {
+	// Decimals can be written as numbers or strings.
+	cases := []struct {
+		format string
+		file   string
+	}{
+		{TOML, "decks = 6\nblackjackRatio = 1.2\n\n[limits]\nmin = 10\nunit = 2.5\n"},
+		{TOML, "decks = 6\nblackjackRatio = \"1.2\"\n\n[limits]\nmin = \"10\"\nunit = \"2.5\"\n"},
+		{YAML, "decks: 6\nblackjackRatio: 1.2\nlimits:\n  min: 10\n  unit: 2.5\n"},
+		{YAML, "decks: 6\nblackjackRatio: \"1.2\"\nlimits:\n  min: \"10\"\n  unit: \"2.5\"\n"},
+		{JSON, `{"decks": 6, "blackjackRatio": "1.2", "limits": {"min": 10, "unit": "2.5"}}`},
+	}
+
+	for _, c := range cases {
+		rs, err := DecodeRuleSet(strings.NewReader(c.file), c.format)
+		if err != nil {
+			t.Errorf("%s %q: %v", c.format, c.file, err)
+			continue
+		}
+		l := rs.TableLimits
+		if !rs.BJRatio.Equal(decimal.New(12, -1)) || !l.Min.Equal(decimal.New(10, 0)) || !l.Unit.Equal(decimal.New(25, -1)) {
+			t.Errorf("%s %q: got ratio %v, minimum %v and unit %v, want: 1.2, 10 and 2.5",
+				c.format, c.file, rs.BJRatio, l.Min, l.Unit)
+		}
+	}
+}
+
+func TestDecodeRuleSetErrors(t *testing.T) {
+	cases := []struct {
+		format string
+		file   string
+	}{
+		{JSON, `{"decks": 6, "blackjackRatio": 1.5, "unknown": true}`},
+		{JSON, `{"decks": 6, "blackjackRatio": 1.5, "peek": "Sometimes"}`},
+		{TOML, "decks = 6\nblackjackRatio = \"1.5\"\nunknown = true\n"},
+		{YAML, "decks: 6\nblackjackRatio: 1.5\nunknown: true\n"},
+		{"ini", "decks=6"},
+	}
+
+	for _, c := range cases {
+		_, err := DecodeRuleSet(strings.NewReader(c.file), c.format)
+		if err == nil {
+			t.Errorf("%s %q: no error", c.format, c.file)
+		}
+	}
+}
+
+func TestRuleSetValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		edit func(rs *RuleSet)
+	}{
+		{"NoDecks", func(rs *RuleSet) { rs.Decks = 0 }},
+		{"NoRatio", func(rs *RuleSet) { rs.BJRatio = decimal.Zero }},
+		{"OneHand", func(rs *RuleSet) { rs.MaxHands = 1 }},
+		{"ResplitAces", func(rs *RuleSet) { rs.MaxHands = 2 }},
+		{"DoubleSplitAces", func(rs *RuleSet) { rs.DSA, rs.DAS = true, false }},
+		{"SurrenderUpCard", func(rs *RuleSet) { rs.SurrenderRule = NoSurrender }},
+		{"PeekNoHoleCard", func(rs *RuleSet) { rs.ENHC = true }},
+		{"EvenMoney", func(rs *RuleSet) { rs.InsuranceBets = false }},
+		{"PerfectPair", func(rs *RuleSet) { rs.PerfectPairs = true }},
+		{"UnknownOption", func(rs *RuleSet) { rs.PeekRule = 10 }},
+		{"Payouts", func(rs *RuleSet) { rs.PayoutTable.Win = decimal.New(-1, 0) }},
+		{"NegativeLimit", func(rs *RuleSet) { rs.TableLimits.Unit = decimal.New(-1, 0) }},
+		{"MinAboveMax", func(rs *RuleSet) { rs.TableLimits.Min, rs.TableLimits.Max = decimal.New(20, 0), decimal.New(10, 0) }},
+	}
+
+	if err := testRuleSet.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rs := *testRuleSet
+			c.edit(&rs)
+
+			err, ok := rs.Validate().(*RuleSetError)
+			if !ok {
+				t.Fatalf("got error %v, want: *RuleSetError", err)
+			}
+			if len(err.Problems) != 1 {
+				t.Errorf("got problems %q, want 1", err.Problems)
+			}
+		})
+	}
+}
+
+func TestNewRuleSet(t *testing.T) {
+	for _, r := range []Rules{HollandCasino, TapTapBoom} {
+		rs := NewRuleSet("test", r)
+		if err := rs.Validate(); err != nil {
+			t.Error(err)
+		}
+		if got, want := NewRuleSet("test", rs), rs; !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %+v, want: %+v", got, want)
+		}
+	}
+}
+
+func TestLoadRuleSet(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "Test Table.yml")
+	file := strings.Replace(testRuleSetFiles[YAML], "name: Test\n", "", 1)
+	if err := os.WriteFile(name, []byte(file), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := LoadRuleSet(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Name != "Test Table" {
+		t.Errorf("got name %q, want: %q", rs.Name, "Test Table")
+	}
+
+	n := len(AvailableRules)
+	defer func(rules []Rules) { AvailableRules = rules }(AvailableRules)
+	if err := LoadAvailableRules(name); err != nil {
+		t.Fatal(err)
+	}
+	if len(AvailableRules) != n+1 {
+		t.Errorf("got %d available rules, want: %d", len(AvailableRules), n+1)
+	}
+}
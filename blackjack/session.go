@@ -0,0 +1,401 @@
+package blackjack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrNoPlayer is returned by Table.Rejoin for a seat without a player.
+var ErrNoPlayer = errors.New("blackjack: no player at seat")
+
+// sessionVersion is the version of the saved table format.
+const sessionVersion = 1
+
+// drawsPerCard is the most values drawn from the random source for a card
+// that is dealt and shuffled back in a round, with room for the draws of
+// the buckets that are full.
+const drawsPerCard = 16
+
+// session is the state of a table as it is saved by Table.Save.
+type session struct {
+	Version  int               `json:"version"`
+	Rules    *RuleSet          `json:"rules"`
+	Seed     int64             `json:"seed"`
+	Draws    uint64            `json:"draws"` // values drawn from the random source
+	Shoe     [][]card.Card     `json:"shoe"`  // cards in the shoe by bucket
+	Dealer   Hand              `json:"dealer"`
+	Revealed bool              `json:"revealed"`
+	Peeked   bool              `json:"peeked"`
+	Step     step              `json:"step"`
+	Cur      int               `json:"cur"`
+	Hand     int               `json:"hand"`
+	Rounds   int               `json:"rounds"`
+	Fortunes []*player.Fortune `json:"fortunes"`
+	Players  []sessionPlayer   `json:"players"`
+	Games    []sessionGame     `json:"games"` // players seated or in the round
+	Seats    [MaxSeats]int     `json:"seats"` // index in Games, -1 for an empty seat
+	Round    []int             `json:"round"` // index in Games of the players in the round
+}
+
+// sessionPlayer is the summary of a player.
+type sessionPlayer struct {
+	Summary Summary   `json:"summary"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
+
+// sessionGame is the state of a player at a seat.
+type sessionGame struct {
+	Seat    int             `json:"seat"`
+	Fortune int             `json:"fortune"` // index in Fortunes
+	Player  int             `json:"player"`  // index in Players
+	Bets    []sessionBet    `json:"bets,omitempty"`
+	Insured decimal.Decimal `json:"insured"`
+}
+
+// sessionBet is a bet of a player in the round.
+type sessionBet struct {
+	Hand      Hand            `json:"hand"`
+	Amount    decimal.Decimal `json:"amount"`
+	Doubled   bool            `json:"doubled,omitempty"`
+	Blackjack bool            `json:"blackjack,omitempty"`
+	SplitAces bool            `json:"splitAces,omitempty"`
+	Bonus     BonusHand       `json:"bonus,omitempty"`
+	Done      bool            `json:"done,omitempty"`
+	Settled   bool            `json:"settled,omitempty"`
+}
+
+// Save writes the state of the table to w: the rules, the cards in the
+// shoe and the state of its random source, the fortunes and summaries of
+// the players, and the round in play. ResumeTable continues the table
+// where it is saved, so a table is best saved while it waits for a move,
+// like from a UI that is asked for one.
+//
+// The UIs, observers and move timeout of the table are not saved. A
+// table that stopped with a fatal error can't be saved, nor can a table
+// with a shoe that is not shuffled from its seed.
+func (t *Table) Save(w io.Writer) error {
+	if t.err != nil {
+		return t.err
+	}
+	sh, ok := t.shuffler.(*card.Shuffler)
+	if !ok || t.src == nil {
+		return errors.New("blackjack: shoe of table can't be saved")
+	}
+
+	var rs *RuleSet
+	switch r := t.rules.(type) {
+	case *RuleSet:
+		rs = r
+	case preset:
+		rs = r.RuleSet
+	default:
+		rs = NewRuleSet(RulesName(t.rules), t.rules)
+	}
+	s := &session{
+		Version:  sessionVersion,
+		Rules:    rs,
+		Seed:     t.seed,
+		Draws:    t.src.draws,
+		Shoe:     sh.Buckets(),
+		Dealer:   t.dealer,
+		Revealed: t.revealed,
+		Peeked:   t.peeked,
+		Step:     t.step,
+		Cur:      t.cur,
+		Hand:     t.hand,
+		Rounds:   t.rounds,
+	}
+
+	players := make(map[*Summary]int)
+	for i, sum := range t.players {
+		players[sum] = i
+		s.Players = append(s.Players, sessionPlayer{Summary: *sum, Start: sum.start, End: sum.end})
+	}
+
+	fortunes := make(map[*player.Fortune]int)
+	games := make(map[*game]int)
+	add := func(g *game) int {
+		if i, ok := games[g]; ok {
+			return i
+		}
+		f, ok := fortunes[g.fortune]
+		if !ok {
+			f = len(s.Fortunes)
+			fortunes[g.fortune] = f
+			s.Fortunes = append(s.Fortunes, g.fortune)
+		}
+
+		sg := sessionGame{Seat: g.seat, Fortune: f, Player: players[g.sum], Insured: g.insured}
+		for _, b := range g.bets {
+			sg.Bets = append(sg.Bets, sessionBet{
+				Hand:      b.hand,
+				Amount:    b.amount,
+				Doubled:   b.doubled,
+				Blackjack: b.blackjack,
+				SplitAces: b.splitAces,
+				Bonus:     b.bonus,
+				Done:      b.done,
+				Settled:   b.settled,
+			})
+		}
+		games[g] = len(s.Games)
+		s.Games = append(s.Games, sg)
+		return games[g]
+	}
+
+	for i, g := range t.seats {
+		s.Seats[i] = -1
+		if g != nil {
+			s.Seats[i] = add(g)
+		}
+	}
+	for _, g := range t.games {
+		s.Round = append(s.Round, add(g))
+	}
+
+	return json.NewEncoder(w).Encode(s)
+}
+
+// ResumeTable returns the table saved by Table.Save to r. The table
+// continues with the move it waited for when it was saved, the events of
+// getting to that move, like the hand in play, are reported again by the
+// next Apply or by Play before the move is asked.
+//
+// The players are seated without a UI, Rejoin and RejoinBoxes give them
+// one.
+func ResumeTable(r io.Reader) (*Table, error) {
+	s := new(session)
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, fmt.Errorf("blackjack: resume table: %v", err)
+	}
+	if err := s.check(); err != nil {
+		return nil, fmt.Errorf("blackjack: resume table: %v", err)
+	}
+
+	t := &Table{}
+	t.rules = s.Rules
+	t.seed = s.Seed
+	t.src = newSource(s.Seed, s.Draws)
+	t.shuffler = card.RestoreShuffler(s.Shoe, t.src)
+	t.dealer = s.Dealer
+	t.revealed = s.Revealed
+	t.peeked = s.Peeked
+	t.step = s.Step
+	t.cur = s.Cur
+	t.hand = s.Hand
+	t.rounds = s.Rounds
+
+	for _, p := range s.Players {
+		sum := p.Summary
+		sum.Duration = 0
+		sum.start, sum.end = p.Start, p.End
+		t.players = append(t.players, &sum)
+	}
+
+	games := make([]*game, len(s.Games))
+	for i, sg := range s.Games {
+		g := &game{
+			table:   &t.table,
+			seat:    sg.Seat,
+			fortune: s.Fortunes[sg.Fortune],
+			sum:     t.players[sg.Player],
+			insured: sg.Insured,
+		}
+		for _, b := range sg.Bets {
+			g.bets = append(g.bets, &bet{
+				hand:      b.Hand,
+				amount:    b.Amount,
+				doubled:   b.Doubled,
+				blackjack: b.Blackjack,
+				splitAces: b.SplitAces,
+				bonus:     b.Bonus,
+				done:      b.Done,
+				settled:   b.Settled,
+			})
+		}
+		games[i] = g
+	}
+	for seat, i := range s.Seats {
+		if i >= 0 {
+			t.seats[seat] = games[i]
+		}
+	}
+	for _, i := range s.Round {
+		t.games = append(t.games, games[i])
+	}
+	return t, nil
+}
+
+// check checks that the saved state is consistent.
+func (s *session) check() error {
+	if s.Version != sessionVersion {
+		return fmt.Errorf("unknown version %d", s.Version)
+	}
+	if s.Rules == nil {
+		return errors.New("no rules")
+	}
+	if err := s.Rules.Validate(); err != nil {
+		return err
+	}
+	if len(s.Shoe) == 0 {
+		return errors.New("no shoe")
+	}
+	if s.Step < stepBet || s.Step > stepClosed {
+		return fmt.Errorf("unknown step %d", s.Step)
+	}
+	for _, f := range s.Fortunes {
+		if f == nil {
+			return errors.New("no fortune")
+		}
+	}
+	for i, g := range s.Games {
+		if g.Seat < 0 || g.Seat >= MaxSeats ||
+			g.Fortune < 0 || g.Fortune >= len(s.Fortunes) ||
+			g.Player < 0 || g.Player >= len(s.Players) {
+			return fmt.Errorf("invalid player %d", i)
+		}
+	}
+	for _, i := range s.Seats {
+		if i < -1 || i >= len(s.Games) {
+			return fmt.Errorf("invalid seated player %d", i)
+		}
+	}
+	for _, i := range s.Round {
+		if i < 0 || i >= len(s.Games) {
+			return fmt.Errorf("invalid player %d in round", i)
+		}
+	}
+	if s.Rounds < 0 {
+		return fmt.Errorf("invalid rounds %d", s.Rounds)
+	}
+	perRound := uint64(52*s.Rules.NumDecks()) * drawsPerCard
+	if s.Draws/perRound > uint64(s.Rounds) {
+		return fmt.Errorf("invalid draws %d", s.Draws)
+	}
+	return s.checkTurn()
+}
+
+// checkTurn checks the player and hand whose turn it is.
+func (s *session) checkTurn() error {
+	switch s.Step {
+	case stepBet, stepRebet:
+		if s.Cur < 0 || s.Cur > MaxSeats || s.Step == stepRebet && (s.Cur == MaxSeats || s.Seats[s.Cur] < 0) {
+			return fmt.Errorf("invalid current seat %d", s.Cur)
+		}
+		for _, i := range s.Round {
+			if len(s.Games[i].Bets) == 0 {
+				return fmt.Errorf("no bet of player %d in round", i)
+			}
+		}
+
+	case stepPerfectPair, stepEarlySurrender, stepInsurance, stepPlay:
+		if s.Cur < 0 || s.Cur > len(s.Round) {
+			return fmt.Errorf("invalid current player %d", s.Cur)
+		}
+		if len(s.Dealer) == 0 {
+			return errors.New("no dealer hand")
+		}
+		for _, i := range s.Round {
+			bets := s.Games[i].Bets
+			if len(bets) == 0 {
+				return fmt.Errorf("no bet of player %d in round", i)
+			}
+			for _, b := range bets {
+				if len(b.Hand) < 2 {
+					return fmt.Errorf("invalid hand of player %d in round", i)
+				}
+			}
+		}
+		if s.Hand < 0 || s.Step == stepPlay && s.Cur < len(s.Round) && s.Hand > len(s.Games[s.Round[s.Cur]].Bets) {
+			return fmt.Errorf("invalid current hand %d", s.Hand)
+		}
+
+	case stepNewGame:
+		if s.Cur < 0 || s.Cur > len(s.Round) {
+			return fmt.Errorf("invalid current player %d", s.Cur)
+		}
+	}
+	return nil
+}
+
+// Rejoin gives the player at seat of a resumed table UI ui, it returns
+// ErrNoPlayer if no player is seated at seat or plays a round there.
+func (t *Table) Rejoin(seat int, ui UI) error {
+	g, err := t.playerAt(seat)
+	if err != nil {
+		return err
+	}
+	g.ui = ui
+	return nil
+}
+
+// RejoinBoxes gives the player with boxes at seats of a resumed table UI
+// ui, like JoinBoxes.
+func (t *Table) RejoinBoxes(ui BoxUI, seats ...int) error {
+	if len(seats) == 0 {
+		return ErrNoSeat
+	}
+	games := make([]*game, len(seats))
+	for i, seat := range seats {
+		g, err := t.playerAt(seat)
+		if err != nil {
+			return err
+		}
+		games[i] = g
+	}
+
+	p := &boxes{ui: ui, seat: -1}
+	for i, g := range games {
+		g.ui = boxUI{p, seats[i]}
+	}
+	return nil
+}
+
+// playerAt returns the player at seat, or the player in the round at seat
+// that left the seat.
+func (t *Table) playerAt(seat int) (*game, error) {
+	if seat < 0 || seat >= MaxSeats {
+		return nil, ErrNoSeat
+	}
+	if g := t.seats[seat]; g != nil {
+		return g, nil
+	}
+	for _, g := range t.games {
+		if g.seat == seat {
+			return g, nil
+		}
+	}
+	return nil, ErrNoPlayer
+}
+
+// Resume resumes a game of a single player that is saved with Table.Save
+// to r, the player at seat 0 plays on like Play with UI ui. Play seats
+// its player at seat 0, so the game can be saved from a table that is
+// played like Play does.
+func Resume(ui UI, r io.Reader) (Summary, error) {
+	return ResumeContext(context.Background(), ui, r)
+}
+
+// ResumeContext is like Resume but stops when ctx is done, like
+// Table.PlayContext.
+func ResumeContext(ctx context.Context, ui UI, r io.Reader) (Summary, error) {
+	t, err := ResumeTable(r)
+	if err != nil {
+		return Summary{}, err
+	}
+	if err := t.Rejoin(0, ui); err != nil {
+		return Summary{}, err
+	}
+	err = t.PlayContext(ctx)
+	return t.Summaries()[0], err
+}
@@ -0,0 +1,340 @@
+package blackjack
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+// observeGame records the events of table t, without the hands that are
+// reported again when a table is resumed.
+func observeGame(t *Table) *[]Event {
+	var events []Event
+	t.Subscribe(ObserverFunc(func(e Event) {
+		if _, ok := e.(HandEvent); !ok {
+			events = append(events, e)
+		}
+	}))
+	return &events
+}
+
+func TestSaveResume(t *testing.T) {
+	const rounds = 10
+	for _, rules := range AvailableRules {
+		t.Run(RulesName(rules), func(t *testing.T) {
+			for _, saved := range []int{0, 1, 7, 23, 58} {
+				tbl := NewSeededTable(rules, 3)
+				tbl.Sit(1, player.NewFortune(decimal.New(1000, 0)))
+				tbl.JoinBoxes(nil, player.NewFortune(decimal.New(1000, 0)), 3, 5)
+
+				rnd := rand.New(rand.NewSource(3))
+				for i := 0; i < saved; i++ {
+					mustApply(t, tbl, randomMove(tbl, rnd, rounds))
+				}
+
+				var buf bytes.Buffer
+				if err := tbl.Save(&buf); err != nil {
+					t.Fatal(err)
+				}
+				resumed, err := ResumeTable(&buf)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// Both tables go on the same with the same moves.
+				want, got := observeGame(tbl), observeGame(resumed)
+				for n := 0; tbl.State().Phase != PhaseClosed; n++ {
+					if n > 100*rounds {
+						t.Fatalf("session doesn't end: %+v", tbl.State())
+					}
+					ws, gs := tbl.State(), resumed.State()
+					if ws.Phase != gs.Phase || ws.Seat != gs.Seat ||
+						!ws.Fortune.Total().Equal(gs.Fortune.Total()) {
+						t.Fatalf("saved after %d moves: got state %+v, want: %+v", saved, gs, ws)
+					}
+
+					m := randomMove(tbl, rnd, rounds)
+					mustApply(t, tbl, m)
+					mustApply(t, resumed, m)
+				}
+				if s := resumed.State(); s.Phase != PhaseClosed {
+					t.Fatalf("saved after %d moves: got phase %v, want: %v", saved, s.Phase, PhaseClosed)
+				}
+
+				// The amounts are compared as they are printed, the payouts of
+				// the resumed rules can have another exponent.
+				if g, w := fmt.Sprint(*got), fmt.Sprint(*want); g != w {
+					t.Errorf("saved after %d moves: got events %v, want: %v", saved, g, w)
+				}
+				if g, w := sessionSummaries(resumed), sessionSummaries(tbl); g != w {
+					t.Errorf("saved after %d moves: got summaries %s, want: %s", saved, g, w)
+				}
+			}
+		})
+	}
+}
+
+// sessionSummaries prints the summaries of the players at table t
+// without their times.
+func sessionSummaries(t *Table) string {
+	sums := t.Summaries()
+	for i := range sums {
+		sums[i].Duration = 0
+		sums[i].start, sums[i].end = time.Time{}, time.Time{}
+	}
+	return fmt.Sprintf("%+v", sums)
+}
+
+func TestSaveShoe(t *testing.T) {
+	tbl := NewSeededTable(LasVegasStrip, 1)
+	tbl.shuffler = &replayShoe{}
+	if err := tbl.Save(&bytes.Buffer{}); err == nil {
+		t.Error("saved a table with the shoe of a replay")
+	}
+}
+
+func TestRejoin(t *testing.T) {
+	tbl := NewSeededTable(LasVegasStrip, 1)
+	tbl.Sit(1, player.NewFortune(decimal.New(1000, 0)))
+	tbl.JoinBoxes(nil, player.NewFortune(decimal.New(1000, 0)), 3, 5)
+
+	var buf bytes.Buffer
+	if err := tbl.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := ResumeTable(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := resumed.PlayRound(); err != ErrNoUI {
+		t.Errorf("got error %v, want: %v", err, ErrNoUI)
+	}
+	if err := resumed.Rejoin(2, replayUI{}); err != ErrNoPlayer {
+		t.Errorf("got error %v, want: %v", err, ErrNoPlayer)
+	}
+	if err := resumed.Rejoin(MaxSeats, replayUI{}); err != ErrNoSeat {
+		t.Errorf("got error %v, want: %v", err, ErrNoSeat)
+	}
+	if err := resumed.Rejoin(1, replayUI{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.RejoinBoxes(replayUI{}, 3, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	uis := resumed.seatUIs()
+	if _, ok := uis[3].(boxUI); !ok || uis[3].(boxUI).boxes != uis[5].(boxUI).boxes {
+		t.Errorf("got UIs %v and %v at the boxes, want the same player", uis[3], uis[5])
+	}
+	if sums := resumed.Summaries(); len(sums) != 2 {
+		t.Errorf("got %d summaries, want: 2", len(sums))
+	}
+}
+
+func TestResumeTableError(t *testing.T) {
+	cases := []struct {
+		name, data string
+	}{
+		{"Syntax", `{"version": 1`},
+		{"Version", `{"version": 2}`},
+		{"NoRules", `{"version": 1}`},
+		{"NoShoe", `{"version": 1, "rules": {"name": "x", "decks": 1, "splitHands": 2, "blackjackRatio": "1.5", "payouts": {"win": "1", "surrender": "0.5", "insurance": "2"}, "limits": {"min": "1", "max": "100"}}}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ResumeTable(strings.NewReader(c.data))
+			if err == nil || !strings.HasPrefix(err.Error(), "blackjack: resume table: ") {
+				t.Errorf("got error %v, want a resume error", err)
+			}
+		})
+	}
+}
+
+// editSession saves table t, changes the saved fields with edit and
+// returns the edited session.
+func editSession(t *testing.T, tbl *Table, edit func(s map[string]interface{})) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := tbl.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var s map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	edit(s)
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestResumeTableCorrupt(t *testing.T) {
+	// A table that waits for the action of the player on the second hand.
+	tbl := NewSeededTable(LasVegasStrip, 1)
+	tbl.Sit(1, player.NewFortune(decimal.New(1000, 0)))
+	tbl.Sit(4, player.NewFortune(decimal.New(1000, 0)))
+	for tbl.State().Phase != PhaseAction {
+		m := Move{Amount: decimal.New(10, 0)}
+		if tbl.State().Phase == PhaseNewGame {
+			m = Move{Accept: true}
+		}
+		mustApply(t, tbl, m)
+	}
+
+	cases := []struct {
+		name string
+		edit func(s map[string]interface{})
+	}{
+		{"NegativePlayer", func(s map[string]interface{}) { s["cur"] = -1 }},
+		{"Player", func(s map[string]interface{}) { s["cur"] = 3 }},
+		{"NegativeHand", func(s map[string]interface{}) { s["hand"] = -1 }},
+		{"Hand", func(s map[string]interface{}) { s["hand"] = 5 }},
+		{"Seat", func(s map[string]interface{}) { s["step"], s["cur"] = stepBet, MaxSeats+1 }},
+		{"RebetSeat", func(s map[string]interface{}) { s["step"], s["cur"] = stepRebet, 0 }},
+		{"NoBets", func(s map[string]interface{}) {
+			games := s["games"].([]interface{})
+			delete(games[0].(map[string]interface{}), "bets")
+		}},
+		{"NoHand", func(s map[string]interface{}) {
+			games := s["games"].([]interface{})
+			bets := games[0].(map[string]interface{})["bets"].([]interface{})
+			bets[0].(map[string]interface{})["hand"] = nil
+		}},
+		{"NoDealer", func(s map[string]interface{}) { s["dealer"] = nil }},
+		{"Rounds", func(s map[string]interface{}) { s["rounds"] = -1 }},
+		{"Draws", func(s map[string]interface{}) { s["draws"] = uint64(1) << 62 }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := editSession(t, tbl, c.edit)
+			_, err := ResumeTable(strings.NewReader(data))
+			if err == nil || !strings.HasPrefix(err.Error(), "blackjack: resume table: ") {
+				t.Errorf("got error %v, want a resume error", err)
+			}
+		})
+	}
+
+	// The unchanged session resumes.
+	data := editSession(t, tbl, func(map[string]interface{}) {})
+	if _, err := ResumeTable(strings.NewReader(data)); err != nil {
+		t.Error(err)
+	}
+}
+
+// customRules are rules that are not a rule set.
+type customRules struct{ *RuleSet }
+
+func (r customRules) String() string { return "" }
+
+func TestSaveCustomRules(t *testing.T) {
+	rs := NewRuleSet("", LasVegasStrip)
+	rs.PayoutTable.SuitedBlackjack = decimal.New(2, 0)
+	rs.PayoutTable.Hands = []HandPayout{{Cards: 5, Ratio: decimal.New(2, 0)}}
+	rs.BonusHands = Bonuses{Charlie: 6, CharlieRatio: decimal.New(1, 0), Triple7: decimal.New(3, 0)}
+	rs.TableLimits.Max = decimal.New(250, 0)
+	rs.TableLimits.Unit = decimal.New(5, -1)
+	rs.TableLimits.Rounding = RoundHalfUp
+	r := customRules{rs}
+
+	tbl := NewSeededTable(r, 1)
+	tbl.Sit(0, player.NewFortune(decimal.New(1000, 0)))
+	var buf bytes.Buffer
+	if err := tbl.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := ResumeTable(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, want := NewRuleSet("", resumed.Rules()), NewRuleSet("", r)
+	if g, w := fmt.Sprintf("%+v", got), fmt.Sprintf("%+v", want); g != w {
+		t.Errorf("got rules %s, want: %s", g, w)
+	}
+}
+
+// standUI stands on every hand and leaves the table after the round.
+type standUI struct {
+	replayUI
+	asked int
+}
+
+func (ui *standUI) Bet(*player.Fortune) decimal.Decimal            { ui.asked++; return decimal.Zero }
+func (ui *standUI) NewGame(*player.Fortune) bool                   { ui.asked++; return false }
+func (ui *standUI) PerfectPairBet(*player.Fortune) decimal.Decimal { ui.asked++; return decimal.Zero }
+func (ui *standUI) TakeEvenMoney() bool                            { ui.asked++; return false }
+func (ui *standUI) InsuranceBet(_ *player.Fortune, _ decimal.Decimal) decimal.Decimal {
+	ui.asked++
+	return decimal.Zero
+}
+
+func (ui *standUI) NextAction(actions []Action) Action {
+	ui.asked++
+	for _, a := range actions {
+		if a == Stand || a == Continue {
+			return a
+		}
+	}
+	return actions[0]
+}
+
+func TestResume(t *testing.T) {
+	tbl := NewSeededTable(LasVegasStrip, 1)
+	if err := tbl.Sit(0, player.NewFortune(decimal.New(50, 0))); err != nil {
+		t.Fatal(err)
+	}
+	mustApply(t, tbl, Move{Amount: decimal.New(10, 0)})
+
+	var buf bytes.Buffer
+	if err := tbl.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	ui := &standUI{}
+	sum, err := Resume(ui, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Rounds != 1 || sum.Hands != 1 || !sum.Wagered.Equal(decimal.New(10, 0)) {
+		t.Errorf("got summary %+v, want the round in play", sum)
+	}
+	if ui.asked == 0 {
+		t.Error("got no moves asked")
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	errStop := errors.New("stop")
+	tbl := NewSeededTable(LasVegasStrip, 1)
+	ui := &standUI{}
+	if err := tbl.Join(0, ui, player.NewFortune(decimal.New(50, 0))); err != nil {
+		t.Fatal(err)
+	}
+
+	n := 0
+	tbl.Checkpoint = func() error {
+		if n++; n > 1 {
+			return errStop
+		}
+		return nil
+	}
+	if err := tbl.Play(); err != errStop {
+		t.Errorf("got error %v, want: %v", err, errStop)
+	}
+	if ui.asked != 1 {
+		t.Errorf("got %d moves asked, want: 1", ui.asked)
+	}
+}
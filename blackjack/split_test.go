@@ -0,0 +1,130 @@
+package blackjack
+
+import (
+	"testing"
+
+	"github.com/dwlnetnl/cards/card"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSplitAcesOneCard(t *testing.T) {
+	dealer := Hand{card.Spade(card.Ace), card.Spade(card.Eight)}
+	left := Hand{card.Heart(card.Ace), card.Heart(card.Ace)}
+	right := Hand{card.Diamond(card.Ace), card.Heart(card.Ace)}
+
+	want := []event{
+		hand{dealer[:1], Hand{card.Heart(card.Ace), card.Diamond(card.Ace)}},
+		nextAction{[]Action{Hit, Stand, Split, Double}, Split},
+		splitHand{left, right},
+		hand{dealer[:1], left},
+		hand{dealer[:1], right},
+		dealerCard{dealer[1], dealer},
+		outcome{Lost, decimal.New(-10, 0), dealer, left},
+		outcome{Lost, decimal.New(-10, 0), dealer, right},
+	}
+
+	cases := []struct {
+		name  string
+		rules testRules
+	}{
+		{"NoResplit", testRules{}},
+		{"MaxHands", testRules{rsa: true, splitHands: 2}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			testPlay(t, 4177, c.rules, 10, 0, want)
+		})
+	}
+}
+
+func TestResplitAces(t *testing.T) {
+	dealer := Hand{card.Spade(card.Ace), card.Diamond(card.Ace), card.Club(card.Six)}
+	first := Hand{card.Heart(card.Ace), card.Spade(card.Eight)}
+	second := Hand{card.Diamond(card.Ace), card.Heart(card.Ace)}
+	third := Hand{card.Heart(card.Ace), card.Club(card.Five)}
+
+	testPlay(t, 4177, testRules{rsa: true}, 10, 0, []event{
+		hand{dealer[:1], Hand{card.Heart(card.Ace), card.Diamond(card.Ace)}},
+		nextAction{[]Action{Hit, Stand, Split, Double}, Split},
+		splitHand{Hand{card.Heart(card.Ace), card.Heart(card.Ace)}, second},
+		hand{dealer[:1], Hand{card.Heart(card.Ace), card.Heart(card.Ace)}},
+		nextAction{[]Action{Stand, Split}, Split},
+		splitHand{first, third},
+		hand{dealer[:1], first},
+		hand{dealer[:1], second},
+		nextAction{[]Action{Stand, Split}, Stand},
+		hand{dealer[:1], third},
+		dealerCard{dealer[1], dealer[:2]},
+		dealerCard{dealer[2], dealer},
+		outcome{Won, decimal.New(20, 0), dealer, first},
+		outcome{Lost, decimal.New(-10, 0), dealer, second},
+		outcome{Lost, decimal.New(-10, 0), dealer, third},
+	})
+}
+
+func TestHitSplitAces(t *testing.T) {
+	dealer := Hand{
+		card.Diamond(card.Five),
+		card.Spade(card.Two),
+		card.Diamond(card.Eight),
+		card.Heart(card.Four),
+	}
+	left := Hand{card.Diamond(card.Ace), card.Heart(card.Six)}
+	right := Hand{card.Heart(card.Ace), card.Club(card.Nine)}
+
+	cases := []struct {
+		name    string
+		rules   testRules
+		actions []Action
+	}{
+		{"NoDouble", testRules{hsa: true}, []Action{Hit, Stand}},
+		{"Double", testRules{hsa: true, dsa: true}, []Action{Hit, Stand, Double}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			testPlay(t, 15473, c.rules, 10, 0, []event{
+				hand{dealer[:1], Hand{card.Diamond(card.Ace), card.Heart(card.Ace)}},
+				nextAction{[]Action{Hit, Stand, Split, Double}, Split},
+				splitHand{left, right},
+				hand{dealer[:1], left},
+				nextAction{c.actions, Stand},
+				hand{dealer[:1], right},
+				nextAction{c.actions, Stand},
+				dealerCard{dealer[1], dealer[:2]},
+				dealerCard{dealer[2], dealer[:3]},
+				dealerCard{dealer[3], dealer},
+				outcome{Lost, decimal.New(-10, 0), dealer, left},
+				outcome{Won, decimal.New(20, 0), dealer, right},
+			})
+		})
+	}
+}
+
+func TestSplitUnequalTens(t *testing.T) {
+	dealer := Hand{card.Spade(card.Ace), card.Club(card.Six), card.Club(card.Two)}
+	player := Hand{card.Heart(card.Queen), card.Spade(card.Ten)}
+
+	cases := []struct {
+		name    string
+		rules   testRules
+		actions []Action
+	}{
+		{"Allowed", testRules{splitTens: true}, []Action{Hit, Stand, Split, Double}},
+		{"NotAllowed", testRules{}, []Action{Hit, Stand, Double}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			testPlay(t, 65, c.rules, 10, 0, []event{
+				hand{dealer[:1], player},
+				nextAction{c.actions, Stand},
+				dealerCard{dealer[1], dealer[:2]},
+				dealerCard{dealer[2], dealer},
+				outcome{Won, decimal.New(20, 0), dealer, player},
+			})
+		})
+	}
+}
@@ -0,0 +1,518 @@
+package blackjack
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+// Phase represents the kind of move a table waits for.
+type Phase int
+
+// Table phases.
+const (
+	PhaseBet            Phase = iota // main bet, zero to not play
+	PhaseNoFortune                   // the player has no fortune and leaves
+	PhaseNewGame                     // whether to play (another) round
+	PhasePerfectPairBet              // perfect pair side bet, zero for none
+	PhaseInsuranceBet                // insurance bet, zero for none
+	PhaseEvenMoney                   // whether to take even money
+	PhaseAction                      // one of the legal actions
+	PhaseClosed                      // all players left the table
+)
+
+//go:generate stringer -type=Phase
+
+// State is the state of a table while it waits for a move.
+type State struct {
+	Phase        Phase
+	Seat         int             // seat of the player to move
+	Fortune      *player.Fortune // fortune of the player to move
+	Dealer       DealerHand
+	Hand         Hand            // hand in play, if any
+	Bet          decimal.Decimal // bet on the hand in play
+	MaxInsurance decimal.Decimal // maximum bet in PhaseInsuranceBet
+}
+
+// Move is the move of the player whose turn it is, the phase of the table
+// determines which field is used.
+type Move struct {
+	Action Action          // PhaseAction
+	Amount decimal.Decimal // PhaseBet, PhasePerfectPairBet and PhaseInsuranceBet
+	Accept bool            // PhaseNewGame and PhaseEvenMoney
+}
+
+// Errors of moves and of a table that can't continue.
+var (
+	ErrInvalidAction  = errors.New("blackjack: action is not allowed")
+	ErrNegativeAmount = errors.New("blackjack: amount is negative")
+	ErrTableClosed    = errors.New("blackjack: table is closed")
+	ErrNoCards        = errors.New("blackjack: no cards left in the shoe")
+	ErrNoUI           = errors.New("blackjack: player has no UI")
+)
+
+// MoveError is the error of a move that is not valid in the state of the
+// table. The table still waits for a valid move. A split, double or
+// insurance bet that is not within the limits of the table or the fortune
+// has a *BetError.
+type MoveError struct {
+	Phase   Phase
+	Move    Move
+	Allowed []Action // legal actions in PhaseAction
+	Err     error
+}
+
+func (e *MoveError) Error() string {
+	switch e.Err {
+	case ErrInvalidAction:
+		return fmt.Sprintf("blackjack: action %v is not allowed, allowed: %v", e.Move.Action, e.Allowed)
+	case ErrNegativeAmount:
+		return fmt.Sprintf("blackjack: amount %v in %v is negative", e.Move.Amount, e.Phase)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the cause of the error.
+func (e *MoveError) Unwrap() error { return e.Err }
+
+// step is the point in a round where the table waits for a move.
+type step int
+
+const (
+	stepBet step = iota
+	stepRebet
+	stepPerfectPair
+	stepEarlySurrender
+	stepInsurance
+	stepPlay
+	stepNewGame
+	stepClosed
+)
+
+// State returns the state of the table, a table that stopped with a
+// fatal error is closed.
+func (t *Table) State() State {
+	t.advance()
+	s := State{Seat: -1, Dealer: t.dealerHand()}
+	if t.err != nil {
+		s.Phase = PhaseClosed
+		return s
+	}
+
+	g := t.player()
+	if g != nil {
+		s.Seat, s.Fortune = g.seat, g.fortune
+	}
+
+	switch t.step {
+	case stepBet:
+		s.Phase = PhaseBet
+		if g.fortune.Total().IsZero() {
+			s.Phase = PhaseNoFortune
+		}
+	case stepRebet, stepNewGame:
+		s.Phase = PhaseNewGame
+	case stepPerfectPair:
+		s.Phase = PhasePerfectPairBet
+	case stepEarlySurrender, stepPlay:
+		s.Phase = PhaseAction
+	case stepInsurance:
+		s.Phase = PhaseInsuranceBet
+		if g.bets[0].hand.IsBlackjack() && t.rules.EvenMoney() {
+			s.Phase = PhaseEvenMoney
+		} else {
+			s.MaxInsurance = g.maxInsurance()
+		}
+	case stepClosed:
+		s.Phase = PhaseClosed
+	}
+
+	if b := t.inPlay(); b != nil {
+		s.Hand, s.Bet = b.hand, b.amount
+	}
+	return s
+}
+
+// LegalActions returns the actions that can be taken in PhaseAction.
+func (t *Table) LegalActions() []Action {
+	t.advance()
+	if t.err != nil {
+		return nil
+	}
+	switch t.step {
+	case stepEarlySurrender:
+		return []Action{Surrender, Continue}
+	case stepPlay:
+		return t.player().availableActions(t.inPlay())
+	}
+	return nil
+}
+
+// Apply makes move m for the player whose turn it is and plays the round
+// until the next move is needed. It returns the events that happened.
+// An invalid move returns a *MoveError and leaves the table unchanged,
+// other errors are fatal and are returned by every following move.
+func (t *Table) Apply(m Move) ([]Event, error) {
+	t.advance()
+	if t.err != nil {
+		return t.flush(), t.err
+	}
+	if err := t.checkMove(m); err != nil {
+		return nil, err
+	}
+
+	t.apply(m)
+	return t.flush(), t.err
+}
+
+// apply makes valid move m and plays until the next move is needed.
+func (t *Table) apply(m Move) {
+	defer t.stopNoCards()
+
+	g := t.player()
+	e := MoveEvent{Seat: g.seat, Phase: t.State().Phase, Allowed: t.LegalActions(), Move: m}
+	if t.step == stepPlay {
+		e.Hand = t.hand
+	}
+	t.notify(e)
+
+	switch t.step {
+	case stepBet:
+		t.applyBet(g, m.Amount)
+	case stepRebet:
+		if !m.Accept {
+			t.leave(t.cur)
+			t.cur++
+		}
+		t.step = stepBet
+	case stepPerfectPair:
+		g.perfectPair(m.Amount)
+		t.cur++
+	case stepEarlySurrender:
+		g.notify(ActionEvent{Seat: g.seat, Action: m.Action})
+		if m.Action == Surrender {
+			g.settleBet(Surrendered, g.bets[0])
+		}
+		t.cur++
+	case stepInsurance:
+		if t.State().Phase == PhaseEvenMoney {
+			g.takeEvenMoney(m.Accept)
+		} else {
+			g.insure(m.Amount)
+		}
+		t.cur++
+	case stepPlay:
+		g.act(t.inPlay(), m.Action)
+	case stepNewGame:
+		if !m.Accept {
+			for seat, other := range t.seats {
+				if other != nil && other.sum == g.sum {
+					t.leave(seat)
+				}
+			}
+		}
+		t.cur++
+	}
+
+	t.ready = false
+	t.advance()
+}
+
+// flush returns the events of the current move.
+func (t *Table) flush() []Event {
+	events := t.events
+	t.events = nil
+	return events
+}
+
+// DefaultMove returns the move made for a player that doesn't move, like
+// a player that is idle too long: no bets, stand and don't play another
+// round.
+func (t *Table) DefaultMove() Move {
+	var m Move
+	if actions := t.LegalActions(); len(actions) > 0 {
+		m.Action = actions[0]
+		for _, a := range actions {
+			if a == Stand || a == Continue {
+				m.Action = a
+			}
+		}
+	}
+	return m
+}
+
+// Abort ends the round in play. The bets of a round that is not dealt yet
+// are returned, a dealt round is played to the end with the default move
+// for every player. The players stay seated and the table waits for the
+// bets of the next round. It returns the events that happened.
+func (t *Table) Abort() ([]Event, error) {
+	t.advance()
+	switch t.step {
+	case stepBet, stepRebet:
+		for _, g := range t.games {
+			amount := g.bets[0].amount
+			g.fortune.Deposit(amount)
+			g.sum.wager(amount.Neg())
+			g.emit(OutcomeEvent{Seat: g.seat, Outcome: Pushed, Amount: amount})
+			g.bets = nil
+		}
+		t.games = nil
+		t.step, t.cur = stepBet, 0
+	case stepClosed:
+		return t.flush(), t.err
+	default:
+		for t.step != stepNewGame && t.err == nil {
+			t.apply(t.DefaultMove())
+		}
+		t.cur = len(t.games)
+	}
+
+	t.ready = false
+	t.advance()
+	return t.flush(), t.err
+}
+
+// player returns the player whose turn it is.
+func (t *Table) player() *game {
+	switch t.step {
+	case stepBet, stepRebet:
+		return t.seats[t.cur]
+	case stepClosed:
+		return nil
+	}
+	return t.games[t.cur]
+}
+
+// inPlay returns the bet in play.
+func (t *Table) inPlay() *bet {
+	switch t.step {
+	case stepEarlySurrender, stepInsurance:
+		return t.games[t.cur].bets[0]
+	case stepPlay:
+		return t.games[t.cur].bets[t.hand]
+	}
+	return nil
+}
+
+// checkMove returns an error if move m is not valid in the state of the
+// table.
+func (t *Table) checkMove(m Move) error {
+	s := t.State()
+	var err error
+	switch s.Phase {
+	case PhaseClosed:
+		err = ErrTableClosed
+	case PhaseAction:
+		if be := t.checkActionBet(m.Action); be != nil {
+			err = be
+		} else if !validAction(m.Action, t.LegalActions()...) {
+			err = ErrInvalidAction
+		}
+	case PhaseBet, PhasePerfectPairBet, PhaseInsuranceBet:
+		if m.Amount.Sign() < 0 {
+			err = ErrNegativeAmount
+		} else if s.Phase == PhaseInsuranceBet && m.Amount.Sign() > 0 {
+			if be := t.player().checkBet(InsuranceBet, m.Amount); be != nil {
+				err = be
+			}
+		}
+	}
+
+	if err != nil {
+		return &MoveError{Phase: s.Phase, Move: m, Allowed: t.LegalActions(), Err: err}
+	}
+	return nil
+}
+
+// checkActionBet validates the bet of action a, a split or a double the
+// rules allow, like checkBet. It returns nil for other actions.
+func (t *Table) checkActionBet(a Action) *BetError {
+	if t.step != stepPlay {
+		return nil
+	}
+	g, b := t.player(), t.inPlay()
+	kind := SplitBet
+	switch {
+	case a == Split && g.canSplit(b):
+	case a == Double && !b.doubled && g.canDouble(b):
+		kind = DoubleBet
+	default:
+		return nil
+	}
+	return g.checkBet(kind, b.amount)
+}
+
+// applyBet places the main bet of player g, a bet of zero asks whether
+// the player wants to play.
+func (t *Table) applyBet(g *game, amount decimal.Decimal) {
+	if g.fortune.Total().IsZero() {
+		g.emit(NoFortuneEvent{Seat: g.seat})
+		t.leave(t.cur)
+		t.cur++
+		return
+	}
+
+	if amount.IsZero() {
+		t.step = stepRebet
+		return
+	}
+
+	if err := g.checkBet(MainBet, amount); err != nil {
+		g.emit(BetRejectedEvent{Seat: g.seat, Err: err})
+		return
+	}
+
+	g.fortune.Withdrawal(amount)
+	g.sum.wager(amount)
+	g.notify(BetEvent{Seat: g.seat, Kind: MainBet, Amount: amount})
+	g.bets = []*bet{{amount: amount}}
+	t.games = append(t.games, g)
+	t.cur++
+}
+
+// advance plays the round until a move of a player is needed, it does
+// nothing when the table already waits for a move. When the shoe runs
+// out of cards the table stops with a fatal error.
+func (t *Table) advance() {
+	defer t.stopNoCards()
+
+	for !t.ready {
+		switch t.step {
+		case stepBet:
+			for t.cur < MaxSeats && t.seats[t.cur] == nil {
+				t.cur++
+			}
+			if t.cur < MaxSeats {
+				t.ready = true
+				return
+			}
+			if len(t.games) == 0 {
+				t.step = stepClosed
+				continue
+			}
+			t.notify(RoundStartEvent{Round: t.rounds + 1, Seats: t.seatsInRound()})
+			t.deal()
+			t.step, t.cur = stepPerfectPair, 0
+
+		case stepRebet:
+			t.ready = true
+
+		case stepPerfectPair:
+			if t.rules.PerfectPair() && t.cur < len(t.games) {
+				t.ready = true
+				return
+			}
+			t.step, t.cur = stepEarlySurrender, 0
+
+		case stepEarlySurrender:
+			for ; t.cur < len(t.games); t.cur++ {
+				if g := t.games[t.cur]; g.canEarlySurrender() {
+					g.emit(HandEvent{Seat: g.seat, Dealer: t.dealerHand(), Player: g.bets[0].hand})
+					t.ready = true
+					return
+				}
+			}
+			t.step, t.cur = stepInsurance, 0
+
+		case stepInsurance:
+			for ; t.cur < len(t.games); t.cur++ {
+				if t.games[t.cur].offerInsurance() {
+					t.ready = true
+					return
+				}
+			}
+			t.dealerCheck()
+			t.step, t.cur, t.hand = stepPlay, 0, 0
+
+		case stepPlay:
+			for ; t.cur < len(t.games); t.cur, t.hand = t.cur+1, 0 {
+				g := t.games[t.cur]
+				for ; t.hand < len(g.bets); t.hand++ {
+					if g.turn(g.bets[t.hand]) {
+						t.ready = true
+						return
+					}
+				}
+			}
+			t.playDealer()
+			for _, g := range t.games {
+				g.settleInsurance()
+			}
+			t.cleanup()
+			t.notify(RoundEndEvent{Round: t.rounds + 1})
+			t.step, t.cur = stepNewGame, 0
+
+		case stepNewGame:
+			for t.cur < len(t.games) && t.askedBefore(t.cur) {
+				t.cur++
+			}
+			if t.cur < len(t.games) {
+				t.ready = true
+				return
+			}
+			t.games = nil
+			t.rounds++
+			t.step, t.cur = stepBet, 0
+
+		case stepClosed:
+			t.ready = true
+		}
+	}
+}
+
+// askedBefore returns true if the player of game i in the round plays an
+// earlier box in the round, a player is asked once for all boxes whether
+// to play another round.
+func (t *Table) askedBefore(i int) bool {
+	for _, g := range t.games[:i] {
+		if g.sum == t.games[i].sum {
+			return true
+		}
+	}
+	return false
+}
+
+// stopNoCards stops the table with ErrNoCards when a card is drawn from
+// an empty shoe, it must be deferred.
+func (t *Table) stopNoCards() {
+	if r := recover(); r != nil {
+		if r != ErrNoCards {
+			panic(r)
+		}
+		t.err = ErrNoCards
+		t.ready = true
+	}
+}
+
+// seatsInRound returns the seats of the players in the round.
+func (t *table) seatsInRound() []int {
+	seats := make([]int, len(t.games))
+	for i, g := range t.games {
+		seats[i] = g.seat
+	}
+	return seats
+}
+
+// dealerCheck lets the dealer peek and settles the blackjacks of the
+// players, after the insurance bets are placed.
+func (t *table) dealerCheck() {
+	if len(t.playing()) > 0 && t.peek() {
+		for _, g := range t.playing() {
+			b := g.bets[0]
+			if b.hand.IsBlackjack() {
+				g.settleBet(Pushed, b)
+			} else {
+				g.settleBet(DealerBlackjack, b)
+			}
+		}
+	}
+
+	for _, g := range t.playing() {
+		if b := g.bets[0]; b.hand.IsBlackjack() {
+			g.playerBlackjack(b)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package blackjack
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+func mustApply(t *testing.T, tbl *Table, m Move) []Event {
+	t.Helper()
+	events, err := tbl.Apply(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestTableState(t *testing.T) {
+	dealer := Hand{card.Diamond(card.Seven), card.Diamond(card.Jack)}
+	first := Hand{card.Club(card.Ten), card.Club(card.Queen)}
+	second := Hand{card.Club(card.Two), card.Club(card.Five), card.Spade(card.Nine)}
+
+	seededShuffler(27, func() {
+		tbl := NewTable(testRules{})
+		f0 := player.NewFortune(decimal.New(50, 0))
+		f1 := player.NewFortune(decimal.New(50, 0))
+		if err := tbl.Sit(1, f0); err != nil {
+			t.Fatal(err)
+		}
+		if err := tbl.Sit(4, f1); err != nil {
+			t.Fatal(err)
+		}
+
+		steps := []struct {
+			phase   Phase
+			seat    int
+			actions []Action
+			move    Move
+			events  []Event
+		}{
+			{PhaseBet, 1, nil, Move{Amount: decimal.New(10, 0)}, nil},
+			{PhaseBet, 4, nil, Move{Amount: decimal.New(10, 0)}, []Event{
+				HandEvent{Seat: 1, Dealer: DealerHand{hand: dealer[:1]}, Player: first},
+			}},
+			{PhaseAction, 1, []Action{Hit, Stand, Double}, Move{Action: Stand}, []Event{
+				HandEvent{Seat: 4, Dealer: DealerHand{hand: dealer[:1]}, Player: second[:2]},
+			}},
+			{PhaseAction, 4, []Action{Hit, Stand, Double}, Move{Action: Hit}, []Event{
+				HandEvent{Seat: 4, Dealer: DealerHand{hand: dealer[:1]}, Player: second},
+			}},
+			{PhaseAction, 4, []Action{Hit, Stand}, Move{Action: Stand}, []Event{
+				DealerCardEvent{Card: dealer[1], Dealer: dealer},
+				OutcomeEvent{Seat: 1, Outcome: Won, Amount: decimal.New(20, 0), Dealer: dealer, Player: first},
+				OutcomeEvent{Seat: 4, Outcome: Lost, Amount: decimal.New(-10, 0), Dealer: dealer, Player: second},
+			}},
+			{PhaseNewGame, 1, nil, Move{Accept: false}, nil},
+			{PhaseNewGame, 4, nil, Move{Accept: false}, nil},
+			{PhaseClosed, -1, nil, Move{}, nil},
+		}
+
+		for i, step := range steps {
+			s := tbl.State()
+			if s.Phase != step.phase || s.Seat != step.seat {
+				t.Fatalf("#%d: got phase %v seat %d, want: %v seat %d",
+					i+1, s.Phase, s.Seat, step.phase, step.seat)
+			}
+			if got := tbl.LegalActions(); !reflect.DeepEqual(got, step.actions) {
+				t.Errorf("#%d: got actions %v, want: %v", i+1, got, step.actions)
+			}
+			if s.Phase == PhaseClosed {
+				break
+			}
+
+			events := mustApply(t, tbl, step.move)
+			if len(events) != len(step.events) {
+				t.Fatalf("#%d: got events %v, want: %v", i+1, events, step.events)
+			}
+			for j, e := range events {
+				if !reflect.DeepEqual(e, step.events[j]) {
+					t.Errorf("#%d: got event %+v, want: %+v", i+1, e, step.events[j])
+				}
+			}
+		}
+
+		if !f0.Active().Equal(decimal.New(60, 0)) || !f1.Active().Equal(decimal.New(40, 0)) {
+			t.Errorf("got fortunes %v and %v, want: 60 and 40", f0.Active(), f1.Active())
+		}
+	})
+}
+
+func TestTableStateRebet(t *testing.T) {
+	tbl := NewTable(testRules{})
+	if err := tbl.Sit(0, player.NewFortune(decimal.New(50, 0))); err != nil {
+		t.Fatal(err)
+	}
+
+	mustApply(t, tbl, Move{})
+	if s := tbl.State(); s.Phase != PhaseNewGame {
+		t.Fatalf("got phase %v, want: %v", s.Phase, PhaseNewGame)
+	}
+	mustApply(t, tbl, Move{Accept: true})
+	if s := tbl.State(); s.Phase != PhaseBet {
+		t.Fatalf("got phase %v, want: %v", s.Phase, PhaseBet)
+	}
+
+	events := mustApply(t, tbl, Move{Amount: decimal.New(100, 0)})
+	if len(events) != 1 {
+		t.Fatalf("got events %v, want a rejected bet", events)
+	}
+	if e, ok := events[0].(BetRejectedEvent); !ok || e.Err.Problem != BetInsufficientFunds {
+		t.Errorf("got event %+v, want: insufficient funds", events[0])
+	}
+
+	mustApply(t, tbl, Move{})
+	mustApply(t, tbl, Move{Accept: false})
+	if s := tbl.State(); s.Phase != PhaseClosed {
+		t.Errorf("got phase %v, want: %v", s.Phase, PhaseClosed)
+	}
+}
+
+func TestTableStateNoFortune(t *testing.T) {
+	tbl := NewTable(testRules{})
+	if err := tbl.Sit(2, player.NewFortune(decimal.Zero)); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := tbl.State(); s.Phase != PhaseNoFortune || s.Seat != 2 {
+		t.Fatalf("got phase %v seat %d, want: %v seat 2", s.Phase, s.Seat, PhaseNoFortune)
+	}
+	events := mustApply(t, tbl, Move{})
+	if !reflect.DeepEqual(events, []Event{NoFortuneEvent{Seat: 2}}) {
+		t.Errorf("got events %v, want: %v", events, []Event{NoFortuneEvent{Seat: 2}})
+	}
+	if s := tbl.State(); s.Phase != PhaseClosed {
+		t.Errorf("got phase %v, want: %v", s.Phase, PhaseClosed)
+	}
+}
+
+func TestTableMoveErrors(t *testing.T) {
+	seededShuffler(49, func() {
+		tbl := NewTable(testRules{})
+		if err := tbl.Sit(0, player.NewFortune(decimal.New(50, 0))); err != nil {
+			t.Fatal(err)
+		}
+
+		cases := []struct {
+			move Move
+			err  error
+		}{
+			{Move{Amount: decimal.New(-10, 0)}, ErrNegativeAmount},
+			{Move{Amount: decimal.New(10, 0)}, nil},
+			{Move{Action: Split}, ErrInvalidAction},
+			{Move{Action: Continue}, ErrInvalidAction},
+			{Move{Action: Stand}, nil},
+			{Move{Accept: false}, nil},
+			{Move{}, ErrTableClosed},
+		}
+
+		for i, c := range cases {
+			phase := tbl.State().Phase
+			_, err := tbl.Apply(c.move)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("#%d: got error %v, want: %v", i+1, err, c.err)
+			}
+			if err == nil {
+				continue
+			}
+			if e, ok := err.(*MoveError); !ok || e.Phase != phase {
+				t.Errorf("#%d: got error %#v, want: *MoveError in %v", i+1, err, phase)
+			}
+			if tbl.State().Phase != phase {
+				t.Errorf("#%d: invalid move changed phase to %v", i+1, tbl.State().Phase)
+			}
+		}
+	})
+}
+
+// invalidUI makes an invalid action and stands when asked again.
+type invalidUI struct {
+	*testUI
+	invalid int
+}
+
+func (ui *invalidUI) NextAction(actions []Action) Action {
+	if ui.invalid > 0 {
+		ui.invalid--
+		return Split
+	}
+	return ui.testUI.NextAction(actions)
+}
+
+func TestPlayInvalidAction(t *testing.T) {
+	dealer := Hand{card.Club(card.Jack), card.Spade(card.Seven)}
+	cards := Hand{card.Club(card.Ace), card.Heart(card.Eight)}
+	want := []event{
+		hand{dealer[:1], cards},
+		nextAction{[]Action{Hit, Stand, Double}, Stand},
+		dealerCard{dealer[1], dealer},
+		outcome{Won, decimal.New(20, 0), dealer, cards},
+	}
+
+	for _, invalid := range []int{maxInvalidMoves - 1, maxInvalidMoves} {
+		seededShuffler(15, func() {
+			ui := &invalidUI{testUI: &testUI{test: t, bet: 10, want: want}, invalid: invalid}
+			ui.fort = player.NewFortune(decimal.New(50, 0))
+			_, err := Play(ui, testRules{}, ui.fort)
+			if invalid < maxInvalidMoves && err != nil {
+				t.Errorf("%d invalid moves: got error %v", invalid, err)
+			}
+			if invalid == maxInvalidMoves && !errors.Is(err, ErrInvalidAction) {
+				t.Errorf("%d invalid moves: got error %v, want: %v", invalid, err, ErrInvalidAction)
+			}
+		})
+	}
+}
+
+func TestPlayNoUI(t *testing.T) {
+	tbl := NewTable(testRules{})
+	if err := tbl.Sit(0, player.NewFortune(decimal.New(50, 0))); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Play(); err != ErrNoUI {
+		t.Errorf("got error %v, want: %v", err, ErrNoUI)
+	}
+}
+
+func TestTableNoCards(t *testing.T) {
+	old := newShuffler
+	newShuffler = func(d card.Deck, num uint, _ rand.Source) *card.Shuffler {
+		return card.NewSeededShuffler(d[:2], 1, rand.NewSource(1))
+	}
+	defer func() { newShuffler = old }()
+
+	tbl := NewTable(testRules{})
+	if err := tbl.Sit(0, player.NewFortune(decimal.New(50, 0))); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tbl.Apply(Move{Amount: decimal.New(10, 0)}); err != ErrNoCards {
+		t.Fatalf("got error %v, want: %v", err, ErrNoCards)
+	}
+	if s := tbl.State(); s.Phase != PhaseClosed {
+		t.Errorf("got phase %v, want: %v", s.Phase, PhaseClosed)
+	}
+	if _, err := tbl.Apply(Move{}); err != ErrNoCards {
+		t.Errorf("got error %v, want: %v", err, ErrNoCards)
+	}
+}
+
+// noCardsInHand returns a table with a shoe of 2 to 5 that runs dry after
+// the deal, while the player is on the move.
+func noCardsInHand(t *testing.T) *Table {
+	t.Helper()
+	old := newShuffler
+	newShuffler = func(d card.Deck, num uint, _ rand.Source) *card.Shuffler {
+		return card.NewSeededShuffler(d[:4], 1, rand.NewSource(1))
+	}
+	defer func() { newShuffler = old }()
+
+	tbl := NewTable(testRules{})
+	if err := tbl.Sit(0, player.NewFortune(decimal.New(50, 0))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tbl.Apply(Move{Amount: decimal.New(10, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Phase != PhaseAction {
+		t.Fatalf("got phase %v, want: %v", s.Phase, PhaseAction)
+	}
+	return tbl
+}
+
+func TestTableNoCardsInHand(t *testing.T) {
+	for _, a := range []Action{Hit, Double, Stand} {
+		tbl := noCardsInHand(t)
+		var err error
+		for err == nil && tbl.State().Phase == PhaseAction {
+			_, err = tbl.Apply(Move{Action: a})
+		}
+		if err != ErrNoCards {
+			t.Errorf("%v: got error %v, want: %v", a, err, ErrNoCards)
+		}
+		if s := tbl.State(); s.Phase != PhaseClosed {
+			t.Errorf("%v: got phase %v, want: %v", a, s.Phase, PhaseClosed)
+		}
+	}
+}
+
+func TestAbortNoCards(t *testing.T) {
+	tbl := noCardsInHand(t)
+	if _, err := tbl.Abort(); err != ErrNoCards {
+		t.Errorf("got error %v, want: %v", err, ErrNoCards)
+	}
+	if _, err := tbl.Apply(Move{}); err != ErrNoCards {
+		t.Errorf("got error %v, want: %v", err, ErrNoCards)
+	}
+}
@@ -0,0 +1,104 @@
+package blackjack
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Summary is the summary of the session of a player at a table.
+type Summary struct {
+	Seat        int // first seat of the player
+	Rounds      int
+	Hands       int // hands settled, split hands count on their own
+	Won         int // including blackjacks
+	Lost        int
+	Pushed      int
+	Surrendered int
+	Blackjacks  int
+	Wagered     decimal.Decimal // main bets including doubles and splits
+	Net         decimal.Decimal // net result of all bets
+	BiggestWin  decimal.Decimal // most won on a hand
+	BiggestLoss decimal.Decimal // most lost on a hand, as a positive amount
+	PerfectPair SideBetSummary
+	Insurance   SideBetSummary
+	Duration    time.Duration
+
+	start, end time.Time
+}
+
+// SideBetSummary is the summary of the side bets of one kind.
+type SideBetSummary struct {
+	Bets    int
+	Won     int
+	Wagered decimal.Decimal
+	Net     decimal.Decimal
+}
+
+func (s *SideBetSummary) add(stake, deposited decimal.Decimal) {
+	s.Bets++
+	if deposited.Sign() > 0 {
+		s.Won++
+	}
+	s.Wagered = s.Wagered.Add(stake)
+	s.Net = s.Net.Add(deposited.Sub(stake))
+}
+
+// wager records a main bet, double or split of amount.
+func (s *Summary) wager(amount decimal.Decimal) {
+	s.Wagered = s.Wagered.Add(amount)
+}
+
+// hand records a settled hand with outcome o and net result.
+func (s *Summary) hand(o Outcome, result decimal.Decimal) {
+	s.Hands++
+	switch o {
+	case Won, Bonus:
+		s.Won++
+	case Blackjack, EvenMoney:
+		s.Won++
+		s.Blackjacks++
+	case Lost, Bust, DealerBlackjack:
+		s.Lost++
+	case Pushed:
+		s.Pushed++
+	case Surrendered:
+		s.Surrendered++
+	}
+
+	s.Net = s.Net.Add(result)
+	if result.Cmp(s.BiggestWin) > 0 {
+		s.BiggestWin = result
+	}
+	if loss := result.Neg(); loss.Cmp(s.BiggestLoss) > 0 {
+		s.BiggestLoss = loss
+	}
+}
+
+// side records a side bet of stake with deposited paid out.
+func (s *Summary) side(sb *SideBetSummary, stake, deposited decimal.Decimal) {
+	sb.add(stake, deposited)
+	s.Net = s.Net.Add(deposited.Sub(stake))
+}
+
+// session returns the summary with the duration of the session, which
+// lasts until the player left or until now.
+func (s *Summary) session() Summary {
+	sum := *s
+	end := s.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	sum.Duration = end.Sub(s.start)
+	return sum
+}
+
+// Summaries returns the summary of every player that joined the table,
+// in the order they joined. A player with several boxes has one summary.
+func (t *Table) Summaries() []Summary {
+	sums := make([]Summary, len(t.players))
+	for i, s := range t.players {
+		sums[i] = s.session()
+	}
+	return sums
+}
@@ -0,0 +1,92 @@
+package blackjack
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSummary(t *testing.T) {
+	var sums []Summary
+	seededShuffler(27, func() {
+		tbl := NewTable(testRules{})
+		f0 := player.NewFortune(decimal.New(50, 0))
+		f1 := player.NewFortune(decimal.New(50, 0))
+		tbl.Sit(0, f0)
+		tbl.Sit(3, f1)
+
+		moves := []Move{
+			{Amount: decimal.New(10, 0)},
+			{Amount: decimal.New(20, 0)},
+			{Action: Stand},
+			{Action: Hit},
+			{Action: Stand},
+			{Accept: false},
+			{Accept: false},
+		}
+		for _, m := range moves {
+			mustApply(t, tbl, m)
+		}
+		sums = tbl.Summaries()
+	})
+
+	want := []Summary{
+		{Seat: 0, Rounds: 1, Hands: 1, Won: 1, Wagered: decimal.New(10, 0),
+			Net: decimal.New(10, 0), BiggestWin: decimal.New(10, 0)},
+		{Seat: 3, Rounds: 1, Hands: 1, Lost: 1, Wagered: decimal.New(20, 0),
+			Net: decimal.New(-20, 0), BiggestLoss: decimal.New(20, 0)},
+	}
+	if len(sums) != len(want) {
+		t.Fatalf("got %d summaries, want: %d", len(sums), len(want))
+	}
+	for i, got := range sums {
+		w := want[i]
+		if got.Seat != w.Seat || got.Rounds != w.Rounds || got.Hands != w.Hands ||
+			got.Won != w.Won || got.Lost != w.Lost || got.Pushed != w.Pushed {
+			t.Errorf("#%d: got %+v, want: %+v", i, got, w)
+		}
+		if !got.Wagered.Equal(w.Wagered) || !got.Net.Equal(w.Net) ||
+			!got.BiggestWin.Equal(w.BiggestWin) || !got.BiggestLoss.Equal(w.BiggestLoss) {
+			t.Errorf("#%d: got wagered %v net %v win %v loss %v, want: %v %v %v %v", i,
+				got.Wagered, got.Net, got.BiggestWin, got.BiggestLoss,
+				w.Wagered, w.Net, w.BiggestWin, w.BiggestLoss)
+		}
+		if got.Duration <= 0 {
+			t.Errorf("#%d: got duration %v", i, got.Duration)
+		}
+	}
+}
+
+// TestSummaryNet plays random sessions and checks that the net result of
+// the summary is the change of the fortune.
+func TestSummaryNet(t *testing.T) {
+	for _, rules := range AvailableRules {
+		t.Run(RulesName(rules), func(t *testing.T) {
+			seededShuffler(1, func() {
+				tbl := NewTable(rules)
+				f0 := player.NewFortune(decimal.New(10000, 0))
+				f1 := player.NewFortune(decimal.New(10000, 0))
+				tbl.Sit(1, f0)
+				tbl.JoinBoxes(nil, f1, 2, 4)
+				playRandom(t, tbl, rand.New(rand.NewSource(1)), 100)
+
+				sums := tbl.Summaries()
+				for i, f := range []*player.Fortune{f0, f1} {
+					s := sums[i]
+					if got, want := s.Net, f.Total().Sub(decimal.New(10000, 0)); !got.Equal(want) {
+						t.Errorf("#%d: got net %v, want: %v", i, got, want)
+					}
+					if n := s.Won + s.Lost + s.Pushed + s.Surrendered; n != s.Hands {
+						t.Errorf("#%d: got %d outcomes, want: %d hands", i, n, s.Hands)
+					}
+					if s.Rounds != 100 {
+						t.Errorf("#%d: got %d rounds, want: 100", i, s.Rounds)
+					}
+				}
+			})
+		})
+	}
+}
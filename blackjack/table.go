@@ -0,0 +1,567 @@
+package blackjack
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+)
+
+// MaxSeats is the number of seats at a table.
+const MaxSeats = 7
+
+// Errors returned by Table.Join.
+var (
+	ErrNoSeat    = errors.New("blackjack: no such seat")
+	ErrSeatTaken = errors.New("blackjack: seat is taken")
+)
+
+// table is the state of a table that is shared by all players.
+type table struct {
+	rules     Rules
+	seed      int64
+	src       *source // random source of the shoe
+	shuffler  shoe
+	dealer    Hand
+	revealed  bool
+	peeked    bool
+	games     []*game // players in the current round
+	events    []Event // events of the current move
+	observers []*subscription
+}
+
+// Table is a blackjack table where up to MaxSeats players play against
+// one dealer from a shared shoe. Players can join and leave the table
+// between rounds.
+//
+// A table is a state machine: State tells which move it waits for and
+// Apply makes that move. Play drives it with the UI of each player.
+type Table struct {
+	table
+	seats   [MaxSeats]*game
+	step    step
+	cur     int   // seat while betting, otherwise player in the round
+	hand    int   // bet in play of the current player
+	ready   bool  // waiting for a move
+	rounds  int   // rounds played
+	err     error // fatal error
+	players []*Summary
+
+	// MoveTimeout is how long Play waits for the move of a player, after
+	// which the default move is made. Zero means no timeout.
+	MoveTimeout time.Duration
+
+	// Checkpoint, when not nil, is called by Play before it asks for a
+	// move, like to save the table while it waits for the move. Play
+	// stops with the error Checkpoint returns.
+	Checkpoint func() error
+}
+
+// NewTable returns an empty table with game rules r and a shoe that is
+// shuffled with a random seed.
+func NewTable(r Rules) *Table {
+	return NewSeededTable(r, time.Now().UnixNano())
+}
+
+// NewSeededTable returns an empty table with game rules r and a shoe that
+// is shuffled with seed. Tables with the same rules and seed deal the same
+// cards when the same moves are made.
+func NewSeededTable(r Rules, seed int64) *Table {
+	t := &Table{}
+	t.rules = r
+	t.seed = seed
+	t.src = newSource(seed, 0)
+	t.shuffler = newShuffler(card.NewStandardDeck(), r.NumDecks(), t.src)
+	return t
+}
+
+// Rules returns the game rules of the table.
+func (t *Table) Rules() Rules {
+	return t.rules
+}
+
+// Seed returns the seed the shoe of the table is shuffled with.
+func (t *Table) Seed() int64 {
+	return t.seed
+}
+
+// shoe is the shoe cards are dealt from, the cards of a round are
+// shuffled back into it.
+type shoe interface {
+	Draw() (card.Card, bool)
+	Shuffle(cards ...card.Card)
+}
+
+// source is the random source of the shoe. It counts the values drawn
+// from it, so the source of a saved table can be restored to the state
+// it was in.
+type source struct {
+	rand.Source
+	draws uint64
+}
+
+// newSource returns a source seeded with seed of which draws values are
+// drawn.
+func newSource(seed int64, draws uint64) *source {
+	s := &source{Source: rand.NewSource(seed)}
+	for s.draws < draws {
+		s.Int63()
+	}
+	return s
+}
+
+func (s *source) Int63() int64 {
+	s.draws++
+	return s.Source.Int63()
+}
+
+func (s *source) Seed(seed int64) {
+	s.Source.Seed(seed)
+	s.draws = 0
+}
+
+// Join seats a player with a UI at seat, seats are numbered from 0 to
+// MaxSeats-1 and are dealt in that order.
+func (t *Table) Join(seat int, ui UI, f *player.Fortune) error {
+	if seat < 0 || seat >= MaxSeats {
+		return ErrNoSeat
+	}
+	if t.seats[seat] != nil {
+		return ErrSeatTaken
+	}
+
+	t.join(seat, ui, f, t.newSummary(seat))
+	return nil
+}
+
+func (t *Table) join(seat int, ui UI, f *player.Fortune, sum *Summary) {
+	t.seats[seat] = &game{table: &t.table, seat: seat, ui: ui, fortune: f, sum: sum}
+	t.seated()
+}
+
+// newSummary starts the summary of a player that joins at seat.
+func (t *Table) newSummary(seat int) *Summary {
+	sum := &Summary{Seat: seat, start: time.Now()}
+	t.players = append(t.players, sum)
+	return sum
+}
+
+// Sit seats a player without a UI at seat, the moves of the player are
+// made with Apply.
+func (t *Table) Sit(seat int, f *player.Fortune) error {
+	return t.Join(seat, nil, f)
+}
+
+// Leave removes the player at seat from the table. A player in a round
+// is played until the end of the round.
+func (t *Table) Leave(seat int) {
+	if seat < 0 || seat >= MaxSeats {
+		return
+	}
+
+	if t.step == stepRebet && t.cur == seat {
+		t.step = stepBet
+	}
+	t.leave(seat)
+	t.seated()
+}
+
+// leave removes the player at seat, the session of the player ends when
+// the player left all seats.
+func (t *Table) leave(seat int) {
+	g := t.seats[seat]
+	if g == nil {
+		return
+	}
+
+	t.seats[seat] = nil
+	for _, other := range t.seats {
+		if other != nil && other.sum == g.sum {
+			return
+		}
+	}
+	g.sum.end = time.Now()
+}
+
+// seated reopens a closed table and lets the betting continue after the
+// seats changed.
+func (t *Table) seated() {
+	switch t.step {
+	case stepClosed:
+		t.step, t.cur = stepBet, 0
+		fallthrough
+	case stepBet:
+		t.ready = false
+	}
+}
+
+// Seated returns the number of occupied seats at the table.
+func (t *Table) Seated() int {
+	n := 0
+	for _, g := range t.seats {
+		if g != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// maxInvalidMoves is the number of invalid moves in a row a UI can make
+// before Play gives up.
+const maxInvalidMoves = 3
+
+// Play plays rounds until all players left the table. It asks the UI of
+// the player whose turn it is for every move and reports the events to
+// the UI of the players, so all players must have a UI. An invalid move
+// is asked again, Play returns the error when a UI keeps making invalid
+// moves or the table stopped with a fatal error.
+func (t *Table) Play() error {
+	return t.PlayContext(context.Background())
+}
+
+// PlayContext is like Play but stops when ctx is done. The round in play
+// is then ended like Abort does and the error of ctx is returned.
+//
+// When ctx can be done or the table has a move timeout, the UI is asked
+// for a move on another goroutine. A UI that is asked again while an
+// earlier question is still unanswered, is not asked again until it
+// answers, and it is sent events meanwhile. Such a UI must be safe for
+// concurrent use.
+func (t *Table) PlayContext(ctx context.Context) error {
+	for {
+		more, err := t.PlayRoundContext(ctx)
+		if !more || err != nil {
+			return err
+		}
+	}
+}
+
+// PlayRound plays a round with the players at the table, like Play.
+// Players that don't place a bet or don't want to play another round
+// leave the table. It returns false if no players are left.
+func (t *Table) PlayRound() (bool, error) {
+	return t.PlayRoundContext(context.Background())
+}
+
+// PlayRoundContext is like PlayRound but stops when ctx is done, like
+// PlayContext.
+func (t *Table) PlayRoundContext(ctx context.Context) (bool, error) {
+	invalid := 0
+	for n := t.rounds; t.rounds == n; {
+		s := t.State()
+		if s.Phase == PhaseClosed {
+			return false, t.err
+		}
+		// Events of getting to the move, like the hand in play of a
+		// resumed table, are reported before the move is asked.
+		if len(t.events) > 0 {
+			t.report(t.seatUIs(), t.games, t.flush())
+		}
+		if t.Checkpoint != nil {
+			if err := t.Checkpoint(); err != nil {
+				return false, err
+			}
+		}
+
+		g := t.player()
+		if g.ui == nil {
+			return true, ErrNoUI
+		}
+
+		m, err := t.ask(ctx, g, s)
+		if err != nil {
+			return false, t.abort(err)
+		}
+
+		uis, games := t.seatUIs(), t.games
+		events, err := t.Apply(m)
+		t.report(uis, games, events)
+
+		if me, ok := err.(*MoveError); ok {
+			// A rejected bet is asked again, it is not an invalid move.
+			if be, ok := me.Err.(*BetError); ok {
+				g.ui.BetRejected(be)
+				continue
+			}
+			if invalid++; invalid < maxInvalidMoves {
+				continue
+			}
+		}
+		if err != nil {
+			return false, err
+		}
+		invalid = 0
+	}
+	return t.State().Phase != PhaseClosed, nil
+}
+
+// abort ends the round in play because of err, which is returned unless
+// the table stopped with a fatal error.
+func (t *Table) abort(err error) error {
+	uis, games := t.seatUIs(), t.games
+	events, fatal := t.Abort()
+	t.report(uis, games, events)
+	if fatal != nil {
+		return fatal
+	}
+	return err
+}
+
+// ask asks the UI of player g for a move in state s. A player that is
+// idle longer than the move timeout makes the default move.
+func (t *Table) ask(ctx context.Context, g *game, s State) (Move, error) {
+	if err := ctx.Err(); err != nil {
+		return Move{}, err
+	}
+	if ctx.Done() == nil && t.MoveTimeout <= 0 {
+		return prompt(g.ui, s, t.LegalActions()), nil
+	}
+
+	var timeout <-chan time.Time
+	if t.MoveTimeout > 0 {
+		timer := time.NewTimer(t.MoveTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		asked := g.answer == nil
+		if asked {
+			ch := make(chan Move, 1)
+			go func(ui UI, legal []Action) {
+				ch <- prompt(ui, s, legal)
+			}(g.ui, t.LegalActions())
+			g.answer = ch
+		}
+
+		select {
+		case m := <-g.answer:
+			g.answer = nil
+			if asked {
+				return m, nil
+			}
+			// the answer to an earlier question, ask again
+		case <-timeout:
+			return t.DefaultMove(), nil
+		case <-ctx.Done():
+			select {
+			case m := <-g.answer:
+				g.answer = nil
+				if asked {
+					return m, nil
+				}
+			default:
+			}
+			return Move{}, ctx.Err()
+		}
+	}
+}
+
+// prompt asks ui for a move in state s.
+func prompt(ui UI, s State, legal []Action) Move {
+	var m Move
+	switch s.Phase {
+	case PhaseBet:
+		m.Amount = ui.Bet(s.Fortune)
+	case PhaseNewGame:
+		m.Accept = ui.NewGame(s.Fortune)
+	case PhasePerfectPairBet:
+		m.Amount = ui.PerfectPairBet(s.Fortune)
+	case PhaseInsuranceBet:
+		m.Amount = ui.InsuranceBet(s.Fortune, s.MaxInsurance)
+	case PhaseEvenMoney:
+		m.Accept = ui.TakeEvenMoney()
+	case PhaseAction:
+		m.Action = ui.NextAction(legal)
+	}
+	return m
+}
+
+// report reports the events of a move to the UI of the players, uis are
+// the UIs at the seats and games the players in the round before the move.
+func (t *Table) report(uis [MaxSeats]UI, games []*game, events []Event) {
+	// A move adds players to the round while betting or ends the round,
+	// either way the longest is the round the events are about.
+	if len(t.games) > len(games) {
+		games = t.games
+	}
+	round := roundUIs(games)
+	for _, e := range events {
+		dispatch(uis, round, e)
+	}
+}
+
+// seatUIs returns the UI of the player at every seat, including the
+// players in the round that left their seat.
+func (t *Table) seatUIs() [MaxSeats]UI {
+	var uis [MaxSeats]UI
+	for _, g := range t.games {
+		uis[g.seat] = g.ui
+	}
+	for i, g := range t.seats {
+		if g != nil {
+			uis[i] = g.ui
+		}
+	}
+	return uis
+}
+
+// deal deals a card to each player in seat order and the up card of the
+// dealer, then a second card to each player and the hole card of the
+// dealer when it takes one.
+func (t *table) deal() {
+	played := make(map[*Summary]bool)
+	for _, g := range t.games {
+		g.bets[0].hand = Hand{t.dealTo(g.seat, 0, false)}
+		if !played[g.sum] {
+			played[g.sum] = true
+			g.sum.Rounds++
+		}
+	}
+	t.dealer = Hand{t.dealTo(DealerSeat, 0, false)}
+
+	for _, g := range t.games {
+		g.bets[0].hand = append(g.bets[0].hand, t.dealTo(g.seat, 0, false))
+	}
+	if !t.rules.NoHoleCard() {
+		t.dealer = append(t.dealer, t.dealTo(DealerSeat, 0, true))
+	}
+}
+
+// playing returns the players in the round with bets that are not
+// settled.
+func (t *table) playing() []*game {
+	var games []*game
+	for _, g := range t.games {
+		if g.playing() {
+			games = append(games, g)
+		}
+	}
+	return games
+}
+
+// peek lets the dealer check the hole card for blackjack when the rules
+// and up-card allow it. It returns true if the dealer has blackjack.
+func (t *table) peek() bool {
+	if t.rules.NoHoleCard() || len(t.dealer) < 2 {
+		return false
+	}
+
+	switch t.rules.Peek() {
+	case NoPeek:
+		return false
+	case PeekAce:
+		if t.dealer[0].Rank != card.Ace {
+			return false
+		}
+	case PeekAceTen:
+		if !t.upCardCanBlackjack() {
+			return false
+		}
+	}
+
+	t.peeked = true
+	bj := t.dealer.IsBlackjack()
+	t.emit(DealerPeekEvent{Blackjack: bj})
+	for _, g := range t.games {
+		g.settleInsurance()
+	}
+	return bj
+}
+
+// upCardCanBlackjack returns true if the dealer up-card is an ace or a
+// ten-valued card.
+func (t *table) upCardCanBlackjack() bool {
+	return card.BlackjackPoints.Points(t.dealer[0]) >= 10
+}
+
+// playDealer plays the dealer hand once, after all players have played,
+// and settles the bets that are left.
+func (t *table) playDealer() {
+	games := t.playing()
+	if len(games) == 0 {
+		return
+	}
+
+	t.reveal()
+	if t.rules.NoHoleCard() && len(t.dealer) == 1 {
+		t.drawDealer()
+	}
+	for _, g := range t.games {
+		g.settleInsurance()
+	}
+
+	if t.dealer.IsBlackjack() {
+		for _, g := range games {
+			g.dealerBlackjack()
+		}
+		return
+	}
+
+	// The dealer only draws when a hand can be beaten, blackjacks are
+	// settled against the first two cards.
+	drawing := false
+	for _, g := range games {
+		for _, b := range g.bets {
+			drawing = drawing || !b.settled && !b.blackjack
+		}
+	}
+	for drawing && !t.dealerFinished() {
+		t.drawDealer()
+	}
+
+	for _, g := range games {
+		g.settleHands()
+	}
+}
+
+func (t *table) drawDealer() {
+	c := t.dealTo(DealerSeat, 0, false)
+	t.dealer = append(t.dealer, c)
+	t.emit(DealerCardEvent{Card: c, Dealer: t.dealer})
+}
+
+// draw draws a card from the shoe, it panics with ErrNoCards when the shoe
+// is empty which stops the table.
+func (t *table) draw() card.Card {
+	c, ok := t.shuffler.Draw()
+	if !ok {
+		panic(ErrNoCards)
+	}
+	return c
+}
+
+// dealTo draws a card for hand of the player at seat, or for the dealer,
+// and tells the observers.
+func (t *table) dealTo(seat, hand int, faceDown bool) card.Card {
+	c := t.draw()
+	t.notify(CardEvent{Seat: seat, Hand: hand, Card: c, FaceDown: faceDown})
+	return c
+}
+
+func (t *table) dealerFinished() bool {
+	v := t.dealer.Value()
+	if v.Total() == 17 && v.IsSoft() {
+		return !t.rules.DealerHitSoft17()
+	}
+	return v.Total() >= 17
+}
+
+func (t *table) cleanup() {
+	cards := append([]card.Card(nil), t.dealer...)
+	for _, g := range t.games {
+		for _, b := range g.bets {
+			cards = append(cards, b.hand...)
+		}
+		g.cleanup()
+	}
+	t.shuffler.Shuffle(cards...)
+	t.notify(ShuffleEvent{Cards: cards})
+
+	t.dealer = nil
+	t.revealed = false
+	t.peeked = false
+}
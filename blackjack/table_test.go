@@ -0,0 +1,282 @@
+package blackjack
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+// testTable plays at a table with a player at every seat that has a UI,
+// seats without a UI are empty.
+func testTable(t *testing.T, seed int64, rules Rules, uis ...*testUI) {
+	seededShuffler(seed, func() {
+		tbl := NewTable(rules)
+		for i, ui := range uis {
+			if ui == nil {
+				continue
+			}
+			ui.test = t
+			ui.fort = player.NewFortune(decimal.New(50, 0))
+			if err := tbl.Join(i, ui, ui.fort); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tbl.Play(); err != nil {
+			t.Fatal(err)
+		}
+
+		if n := tbl.Seated(); n != 0 {
+			t.Errorf("got %d seated players, want: 0", n)
+		}
+	})
+}
+
+func TestTable(t *testing.T) {
+	rules := testRules{}
+	dealer := Hand{card.Diamond(card.Seven), card.Diamond(card.Jack)}
+	first := Hand{card.Club(card.Ten), card.Club(card.Queen)}
+	second := Hand{card.Club(card.Two), card.Club(card.Five), card.Spade(card.Nine)}
+
+	testTable(t, 27, rules,
+		&testUI{bet: 10, want: []event{
+			hand{dealer[:1], first},
+			nextAction{[]Action{Hit, Stand, Double}, Stand},
+			dealerCard{dealer[1], dealer},
+			outcome{Won, decimal.New(20, 0), dealer, first},
+		}},
+		nil,
+		&testUI{bet: 10, want: []event{
+			hand{dealer[:1], second[:2]},
+			nextAction{[]Action{Hit, Stand, Double}, Hit},
+			hand{dealer[:1], second},
+			nextAction{[]Action{Hit, Stand}, Stand},
+			dealerCard{dealer[1], dealer},
+			outcome{Lost, decimal.New(-10, 0), dealer, second},
+		}},
+	)
+}
+
+func TestDealOrder(t *testing.T) {
+	const seed = 7
+	rules := testRules{holeCard: true}
+	shoe := newShuffler(card.NewStandardDeck(), rules.NumDecks(), rand.NewSource(seed))
+
+	var got []CardEvent
+	seededShuffler(seed, func() {
+		tbl := NewTable(rules)
+		for _, seat := range []int{0, 2, 5} {
+			tbl.Sit(seat, player.NewFortune(decimal.New(50, 0)))
+		}
+		tbl.Subscribe(ObserverFunc(func(e Event) {
+			if e, ok := e.(CardEvent); ok {
+				got = append(got, e)
+			}
+		}))
+		for i := 0; i < 3; i++ {
+			mustApply(t, tbl, Move{Amount: decimal.New(10, 0)})
+		}
+	})
+
+	// One card to each seat in seat order and the up card of the dealer,
+	// then the second round and the hole card.
+	want := []int{0, 2, 5, DealerSeat, 0, 2, 5, DealerSeat}
+	if len(got) < len(want) {
+		t.Fatalf("got %d cards, want: at least %d", len(got), len(want))
+	}
+	for i, seat := range want {
+		c, _ := shoe.Draw()
+		e := got[i]
+		if e.Seat != seat || e.Card != c {
+			t.Errorf("card %d: got %v to seat %d, want: %v to seat %d", i, e.Card, e.Seat, c, seat)
+		}
+		if faceDown := i == len(want)-1; e.FaceDown != faceDown {
+			t.Errorf("card %d: got face down %v, want: %v", i, e.FaceDown, faceDown)
+		}
+	}
+}
+
+func TestTableNoBet(t *testing.T) {
+	rules := testRules{}
+	dealer := Hand{card.Club(card.Jack), card.Spade(card.Seven)}
+	player := Hand{card.Club(card.Ace), card.Heart(card.Eight)}
+
+	testTable(t, 15, rules,
+		&testUI{bet: 0},
+		&testUI{bet: 10, want: []event{
+			hand{dealer[:1], player},
+			nextAction{[]Action{Hit, Stand, Double}, Stand},
+			dealerCard{dealer[1], dealer},
+			outcome{Won, decimal.New(20, 0), dealer, player},
+		}},
+	)
+}
+
+func TestTableJoin(t *testing.T) {
+	tbl := NewTable(testRules{})
+	f := player.NewFortune(decimal.New(50, 0))
+
+	if err := tbl.Join(MaxSeats, &testUI{}, f); err != ErrNoSeat {
+		t.Errorf("got error %v, want: %v", err, ErrNoSeat)
+	}
+	if err := tbl.Join(-1, &testUI{}, f); err != ErrNoSeat {
+		t.Errorf("got error %v, want: %v", err, ErrNoSeat)
+	}
+	if err := tbl.Join(3, &testUI{}, f); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Join(3, &testUI{}, f); err != ErrSeatTaken {
+		t.Errorf("got error %v, want: %v", err, ErrSeatTaken)
+	}
+	if err := tbl.Join(6, &testUI{}, f); err != nil {
+		t.Fatal(err)
+	}
+	if n := tbl.Seated(); n != 2 {
+		t.Errorf("got %d seated players, want: 2", n)
+	}
+
+	tbl.Leave(3)
+	if n := tbl.Seated(); n != 1 {
+		t.Errorf("got %d seated players, want: 1", n)
+	}
+	if err := tbl.Join(3, &testUI{}, f); err != nil {
+		t.Error(err)
+	}
+}
+
+// cancelUI cancels the context when it is asked for an action.
+type cancelUI struct {
+	*testUI
+	cancel context.CancelFunc
+}
+
+func (ui *cancelUI) NextAction(actions []Action) Action {
+	ui.cancel()
+	return ui.testUI.NextAction(actions)
+}
+
+func TestTableCancel(t *testing.T) {
+	rules := testRules{}
+	dealer := Hand{card.Diamond(card.Queen), card.Spade(card.Two), card.Spade(card.Six)}
+	first := Hand{card.Club(card.Three), card.Heart(card.Seven)}
+	second := Hand{card.Club(card.Three), card.Club(card.Three)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f0 := player.NewFortune(decimal.New(50, 0))
+	f1 := player.NewFortune(decimal.New(50, 0))
+	ui0 := &cancelUI{testUI: &testUI{test: t, fort: f0, bet: 10, want: []event{
+		hand{dealer[:1], first},
+		nextAction{[]Action{Hit, Stand, Double}, Stand},
+		dealerCard{dealer[1], dealer[:2]},
+		dealerCard{dealer[2], dealer},
+		outcome{Lost, decimal.New(-10, 0), dealer, first},
+	}}, cancel: cancel}
+	ui1 := &testUI{test: t, fort: f1, bet: 10, want: []event{
+		hand{dealer[:1], second},
+		dealerCard{dealer[1], dealer[:2]},
+		dealerCard{dealer[2], dealer},
+		outcome{Lost, decimal.New(-10, 0), dealer, second},
+	}}
+
+	seededShuffler(17, func() {
+		tbl := NewTable(rules)
+		tbl.Join(0, ui0, f0)
+		tbl.Join(1, ui1, f1)
+		if err := tbl.PlayContext(ctx); err != context.Canceled {
+			t.Errorf("got error %v, want: %v", err, context.Canceled)
+		}
+		if n := tbl.Seated(); n != 2 {
+			t.Errorf("got %d seated players, want: 2", n)
+		}
+	})
+
+	if ui0.idx != len(ui0.want) || ui1.idx != len(ui1.want) {
+		t.Errorf("got %d and %d events, want: %d and %d",
+			ui0.idx, ui1.idx, len(ui0.want), len(ui1.want))
+	}
+	if !f0.Active().Equal(decimal.New(40, 0)) || !f1.Active().Equal(decimal.New(40, 0)) {
+		t.Errorf("got fortunes %v and %v, want: 40 and 40", f0.Active(), f1.Active())
+	}
+}
+
+func TestTableCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	f := player.NewFortune(decimal.New(50, 0))
+	if _, err := PlayContext(ctx, &testUI{test: t, fort: f}, testRules{}, f); err != context.Canceled {
+		t.Errorf("got error %v, want: %v", err, context.Canceled)
+	}
+}
+
+// idleUI never answers when it is asked for an action.
+type idleUI struct {
+	*testUI
+	idle chan struct{}
+}
+
+func (ui *idleUI) NextAction(actions []Action) Action {
+	<-ui.idle
+	return Hit
+}
+
+func TestTableMoveTimeout(t *testing.T) {
+	dealer := Hand{card.Club(card.Jack), card.Spade(card.Seven)}
+	cards := Hand{card.Club(card.Ace), card.Heart(card.Eight)}
+
+	f := player.NewFortune(decimal.New(50, 0))
+	ui := &idleUI{testUI: &testUI{test: t, fort: f, bet: 10, want: []event{
+		hand{dealer[:1], cards},
+		dealerCard{dealer[1], dealer},
+		outcome{Won, decimal.New(20, 0), dealer, cards},
+	}}, idle: make(chan struct{})}
+	defer close(ui.idle)
+
+	seededShuffler(15, func() {
+		tbl := NewTable(testRules{})
+		tbl.MoveTimeout = 10 * time.Millisecond
+		tbl.Join(0, ui, f)
+		if err := tbl.Play(); err != nil {
+			t.Fatal(err)
+		}
+		if n := tbl.Seated(); n != 0 {
+			t.Errorf("got %d seated players, want: 0", n)
+		}
+	})
+
+	if !f.Active().Equal(decimal.New(60, 0)) {
+		t.Errorf("got fortune %v, want: 60", f.Active())
+	}
+}
+
+func TestTableAbort(t *testing.T) {
+	tbl := NewTable(testRules{})
+	f := player.NewFortune(decimal.New(50, 0))
+	tbl.Sit(0, f)
+	tbl.Sit(1, player.NewFortune(decimal.New(50, 0)))
+
+	if _, err := tbl.Apply(Move{Amount: decimal.New(10, 0)}); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := tbl.Abort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Event{OutcomeEvent{Seat: 0, Outcome: Pushed, Amount: decimal.New(10, 0)}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got events %+v, want: %+v", events, want)
+	}
+	if !f.Active().Equal(decimal.New(50, 0)) {
+		t.Errorf("got fortune %v, want: 50", f.Active())
+	}
+	if s := tbl.State(); s.Phase != PhaseBet || s.Seat != 0 {
+		t.Errorf("got phase %v seat %d, want: %v seat 0", s.Phase, s.Seat, PhaseBet)
+	}
+}
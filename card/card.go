@@ -1,6 +1,11 @@
 // Package card defines a playing card data type.
 package card
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Suit represents a playing card suit.
 type Suit int
 
@@ -102,6 +107,42 @@ func (c Card) String() string {
 	return string(c.Suit.Symbol()) + " " + c.Rank.Symbol()
 }
 
+var suitLetters = [...]string{"", "S", "H", "D", "C"}
+
+// MarshalText implements encoding.TextMarshaler. A card is written as its
+// rank symbol followed by the first letter of its suit, like "10S" or
+// "QH", a joker is written as its rank symbol.
+func (c Card) MarshalText() ([]byte, error) {
+	if c.Suit < Naked || c.Suit > Clubs || c.Rank < Two || c.Rank > JokerWhite {
+		return nil, fmt.Errorf("card: invalid card %d/%d", c.Suit, c.Rank)
+	}
+	return []byte(c.Rank.Symbol() + suitLetters[c.Suit]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, it parses a card
+// written by MarshalText.
+func (c *Card) UnmarshalText(text []byte) error {
+	s := string(text)
+	for r, sym := range rankSymbols {
+		rank := Rank(r)
+		if !strings.HasPrefix(s, sym) {
+			continue
+		}
+		suit := strings.ToUpper(s[len(sym):])
+		if rank >= JokerRed && suit == "" {
+			*c = Card{Naked, rank}
+			return nil
+		}
+		for i, l := range suitLetters[Spades:] {
+			if rank < JokerRed && suit == l {
+				*c = Card{Spades + Suit(i), rank}
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("card: invalid card %q", s)
+}
+
 func nonJokerRank(r Rank) Rank {
 	if r == JokerRed || r == JokerBlack || r == JokerWhite {
 		panic("card: joker in normal rank is not allowed")
@@ -0,0 +1,28 @@
+package card
+
+import "testing"
+
+func TestCardText(t *testing.T) {
+	d := append(NewStandardDeck(), RedJoker(), BlackJoker(), WhiteJoker())
+	for _, c := range d {
+		text, err := c.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got Card
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if got != c {
+			t.Errorf("%s: got %v, want: %v", text, got, c)
+		}
+	}
+
+	for _, s := range []string{"", "1S", "10", "QX", "*RS", "AHH"} {
+		var c Card
+		if err := c.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%q: got %v, want error", s, c)
+		}
+	}
+}
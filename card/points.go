@@ -0,0 +1,92 @@
+package card
+
+// A Valuation assigns points to playing cards in a particular game.
+type Valuation interface {
+	Points(c Card) int
+}
+
+// Sum returns the total number of points of cards valued by v.
+func Sum(v Valuation, cards ...Card) int {
+	total := 0
+	for _, c := range cards {
+		total += v.Points(c)
+	}
+	return total
+}
+
+const numRanks = int(JokerWhite) + 1
+
+// RankPoints is a valuation that only depends on the rank of a card.
+// It is indexed by rank.
+type RankPoints [numRanks]int
+
+// Points returns the points of card c.
+func (p RankPoints) Points(c Card) int { return p[c.Rank] }
+
+// SuitPoints is a valuation that depends on both the suit and rank of
+// a card. It is indexed by suit.
+type SuitPoints [Clubs + 1]RankPoints
+
+// Points returns the points of card c.
+func (p SuitPoints) Points(c Card) int { return p[c.Suit][c.Rank] }
+
+// TrumpPoints is a valuation that depends on whether a card is of the
+// trump suit.
+type TrumpPoints struct {
+	Trump  Suit
+	Plain  RankPoints
+	Trumps RankPoints
+}
+
+// Points returns the points of card c.
+func (p TrumpPoints) Points(c Card) int {
+	if c.Suit == p.Trump && c.Suit != Naked {
+		return p.Trumps[c.Rank]
+	}
+	return p.Plain[c.Rank]
+}
+
+// Point values of cards in different games.
+var (
+	// BlackjackPoints counts an ace as 11, it may be counted as 1
+	// depending on the rest of the hand.
+	BlackjackPoints = RankPoints{
+		Two: 2, Three: 3, Four: 4, Five: 5, Six: 6, Seven: 7, Eight: 8,
+		Nine: 9, Ten: 10, Jack: 10, Queen: 10, King: 10, Ace: 11,
+	}
+
+	// BaccaratPoints counts modulo 10, tens and faces are worth nothing.
+	BaccaratPoints = RankPoints{
+		Two: 2, Three: 3, Four: 4, Five: 5, Six: 6, Seven: 7, Eight: 8,
+		Nine: 9, Ace: 1,
+	}
+
+	// CribbagePoints are used for counting to fifteen and thirty-one.
+	CribbagePoints = RankPoints{
+		Two: 2, Three: 3, Four: 4, Five: 5, Six: 6, Seven: 7, Eight: 8,
+		Nine: 9, Ten: 10, Jack: 10, Queen: 10, King: 10, Ace: 1,
+	}
+
+	// BridgeHighCardPoints are the Milton Work high card points.
+	BridgeHighCardPoints = RankPoints{Jack: 1, Queen: 2, King: 3, Ace: 4}
+
+	// HeartsPoints counts every heart as 1 and the queen of spades as 13.
+	HeartsPoints = SuitPoints{
+		Spades: {Queen: 13},
+		Hearts: {
+			Two: 1, Three: 1, Four: 1, Five: 1, Six: 1, Seven: 1, Eight: 1,
+			Nine: 1, Ten: 1, Jack: 1, Queen: 1, King: 1, Ace: 1,
+		},
+	}
+)
+
+var (
+	klaverjasPlain = RankPoints{Ten: 10, Jack: 2, Queen: 3, King: 4, Ace: 11}
+	klaverjasTrump = RankPoints{Nine: 14, Ten: 10, Jack: 20, Queen: 3, King: 4, Ace: 11}
+)
+
+// KlaverjasPoints returns the point values of cards in klaverjas when
+// suit trump is trumps.
+func KlaverjasPoints(trump Suit) TrumpPoints {
+	return TrumpPoints{Trump: trump, Plain: klaverjasPlain, Trumps: klaverjasTrump}
+}
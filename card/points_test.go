@@ -0,0 +1,60 @@
+package card
+
+import "testing"
+
+func TestPointsDeckSum(t *testing.T) {
+	cases := []struct {
+		name string
+		v    Valuation
+		want int
+	}{
+		{"Blackjack", BlackjackPoints, 380},
+		{"Baccarat", BaccaratPoints, 180},
+		{"Cribbage", CribbagePoints, 340},
+		{"BridgeHighCard", BridgeHighCardPoints, 40},
+		{"Hearts", HeartsPoints, 26},
+		{"Klaverjas", KlaverjasPoints(Hearts), 152},
+	}
+
+	d := NewStandardDeck()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Sum(c.v, d...)
+			if got != c.want {
+				t.Errorf("got: %d, want: %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestPoints(t *testing.T) {
+	cases := []struct {
+		v    Valuation
+		c    Card
+		want int
+	}{
+		{BlackjackPoints, Spade(Ace), 11},
+		{BlackjackPoints, Heart(King), 10},
+		{BaccaratPoints, Club(Queen), 0},
+		{BaccaratPoints, Club(Ace), 1},
+		{CribbagePoints, Diamond(Jack), 10},
+		{BridgeHighCardPoints, Spade(Ten), 0},
+		{BridgeHighCardPoints, Spade(King), 3},
+		{HeartsPoints, Spade(Queen), 13},
+		{HeartsPoints, Club(Queen), 0},
+		{HeartsPoints, Heart(Two), 1},
+		{KlaverjasPoints(Clubs), Club(Jack), 20},
+		{KlaverjasPoints(Clubs), Club(Nine), 14},
+		{KlaverjasPoints(Clubs), Heart(Jack), 2},
+		{KlaverjasPoints(Clubs), Heart(Nine), 0},
+		{BlackjackPoints, Joker(), 0},
+		{KlaverjasPoints(Naked), Joker(), 0},
+	}
+
+	for _, c := range cases {
+		got := c.v.Points(c.c)
+		if got != c.want {
+			t.Errorf("%T %v: got: %d, want: %d", c.v, c.c, got, c.want)
+		}
+	}
+}
@@ -50,6 +50,34 @@ func NewSeededShuffler(d Deck, num uint, src rand.Source) *Shuffler {
 	return s
 }
 
+// RestoreShuffler returns a shuffler that holds the cards in buckets, as
+// returned by Buckets. The shuffler is seeded by a random source src, it
+// continues like the shuffler the buckets are of when src is in the same
+// state as the source of that shuffler.
+func RestoreShuffler(buckets [][]Card, src rand.Source) *Shuffler {
+	s := &Shuffler{
+		rand:  rand.New(src),
+		buck:  make([][]Card, len(buckets)),
+		nbuck: len(buckets),
+	}
+
+	for i, b := range buckets {
+		s.buck[i] = append(make([]Card, 0, bucketSize), b...)
+		s.cards += len(b)
+	}
+
+	return s
+}
+
+// Buckets returns the cards in the shuffler by the bucket they are in.
+func (s *Shuffler) Buckets() [][]Card {
+	b := make([][]Card, s.nbuck)
+	for i := range b {
+		b[i] = append([]Card{}, s.buck[i]...)
+	}
+	return b
+}
+
 // Shuffle shuffles zero or more cards back into the deck(s).
 func (s *Shuffler) Shuffle(cards ...Card) {
 	for _, c := range cards {
@@ -42,6 +42,25 @@ func TestShuffler(t *testing.T) {
 	s.MustDraw()
 }
 
+func TestRestoreShuffler(t *testing.T) {
+	s := NewSeededShuffler(NewStandardDeck(), 2, rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		s.MustDraw()
+	}
+
+	// Continue both shufflers with sources in the same state.
+	r := RestoreShuffler(s.Buckets(), rand.NewSource(2))
+	s.rand = rand.New(rand.NewSource(2))
+	testBuckets(t, r.buck, s.buck)
+
+	for i := 0; i < 20; i++ {
+		testCard(t, r.MustDraw(), s.MustDraw())
+	}
+	if r.cards != s.cards {
+		t.Errorf("got %d cards, want: %d", r.cards, s.cards)
+	}
+}
+
 func testBuckets(t *testing.T, got, want [][]Card) {
 	if !reflect.DeepEqual(got, want) {
 		t.Fatal("shuffler buckets differ")
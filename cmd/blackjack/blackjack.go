@@ -3,10 +3,16 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -17,12 +23,276 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+var (
+	rulesFile     = flag.String("rules", "", "load game rules from a JSON, TOML or YAML `file`")
+	rulesNotation = flag.String("table", "", "game `rules` in notation, like \"6D H17 DAS 3:2\"")
+	rulesPreset   = flag.String("preset", "", "play with the game rules of preset `name`")
+	numBoxes      = flag.Int("boxes", 1, "play `n` boxes at once")
+	historyFile   = flag.String("history", "", "append the hand history to `file` as JSON Lines")
+	replayFile    = flag.String("replay", "", "replay the hand history in `file` and check the outcomes")
+	stepReplay    = flag.Bool("step", false, "wait for enter after every move of a replay")
+	sessionFile   = flag.String("session", "", "save the session to `file` before every move and resume it from there")
+)
+
 func main() {
-	ui := &textUI{r: bufio.NewReader(os.Stdin), w: os.Stdout, err: handleError}
-	ui.writeln("Welcome to blackjack!")
+	flag.Parse()
+
+	var rules blackjack.Rules
+	if *rulesPreset != "" {
+		r, ok := blackjack.LookupRules(*rulesPreset)
+		if !ok {
+			handleError(fmt.Errorf("unknown preset %q, available: %s",
+				*rulesPreset, presetNames()))
+		}
+		rules = r
+	}
+	if *rulesFile != "" {
+		rs, err := blackjack.LoadRuleSet(*rulesFile)
+		if err != nil {
+			handleError(err)
+		}
+		rules = rs
+	}
+	if *rulesNotation != "" {
+		rs, err := blackjack.ParseRules(*rulesNotation)
+		if err != nil {
+			handleError(err)
+		}
+		rules = rs
+	}
+
+	ui := &textUI{r: bufio.NewReader(os.Stdin), w: os.Stdout, err: handleError, step: *stepReplay}
+	if *replayFile != "" {
+		replay(ui, *replayFile, rules)
+		return
+	}
+
+	t, f, resumed := newTable(ui, rules)
+	rules = t.Rules()
+	if resumed {
+		ui.writeln("Welcome back to blackjack!")
+	} else {
+		ui.writeln("Welcome to blackjack!")
+	}
+	if name, notation := blackjack.RulesName(rules), blackjack.FormatRules(rules); name != notation {
+		ui.writeln("Rules:", name, "("+notation+")")
+	} else {
+		ui.writeln("Rules:", notation)
+	}
+
+	// An interrupt finishes the round in play and ends the game. A saved
+	// session ends while waiting for input, it resumes with the move that
+	// was asked. The stdin UI has no idle timeout, an unanswered prompt
+	// would keep reading input meant for the next one.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *sessionFile != "" {
+		t.Checkpoint = func() error { return saveSession(t, *sessionFile) }
+	}
+
+	if *historyFile != "" {
+		f, err := os.OpenFile(*historyFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			handleError(err)
+		}
+		defer f.Close()
+		hw := blackjack.NewHistoryWriter(f, t)
+		defer func() {
+			if err := hw.Close(); err != nil {
+				ui.writeln("Writing the hand history failed:", err)
+			}
+		}()
+	}
+
+	var err error
+	if *sessionFile != "" {
+		err = playSession(ui, t, ctx.Done())
+	} else {
+		err = t.PlayContext(ctx)
+	}
+	if err == errInterrupted {
+		ui.writeln()
+		ui.writeln("The session is saved to", *sessionFile)
+		return
+	}
+	if errors.Is(err, context.Canceled) {
+		ui.writeFortune(f)
+	} else if err != nil {
+		handleError(err)
+	}
+	if *sessionFile != "" {
+		// The session is over, the next one starts anew.
+		if err := os.Remove(*sessionFile); err != nil && !os.IsNotExist(err) {
+			ui.writeln("Removing the saved session failed:", err)
+		}
+	}
+	ui.writeSummary(t.Summaries()[0])
+}
+
+// errInterrupted is returned by playSession when the session is
+// interrupted.
+var errInterrupted = errors.New("session interrupted")
+
+// playSession plays at table t until the game ends or interrupted is
+// closed. An interrupt ends the session while ui waits for input, so the
+// saved session and the hand history end at the move that was asked.
+func playSession(ui *textUI, t *blackjack.Table, interrupted <-chan struct{}) error {
+	ui.input = new(sync.Mutex)
+	ui.input.Lock()
+	done := make(chan error, 1)
+	go func() {
+		done <- t.Play() // before the unlock, a locked input sees the result
+		ui.input.Unlock()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-interrupted:
+		ui.input.Lock()
+		select {
+		case err := <-done:
+			return err
+		default:
+			return errInterrupted
+		}
+	}
+}
+
+// newTable returns the table to play at with rules r, or with the default
+// rules when r is nil, and the fortune of the player. It resumes the saved
+// session when there is one.
+func newTable(ui *textUI, r blackjack.Rules) (t *blackjack.Table, f *player.Fortune, resumed bool) {
+	if *sessionFile != "" {
+		t, f, err := resumeSession(ui, *sessionFile)
+		if err != nil && !os.IsNotExist(err) {
+			handleError(err)
+		}
+		if err == nil {
+			if r != nil {
+				handleError(errors.New("the rules of a saved session can't be changed"))
+			}
+			return t, f, true
+		}
+	}
+
+	if r == nil {
+		r = blackjack.HollandCasino
+	}
+	f = player.NewFortune(decimal.New(50, 0))
+	t = blackjack.NewTable(r)
+
+	var err error
+	if *numBoxes <= 1 {
+		err = t.Join(0, ui, f)
+	} else {
+		seats := make([]int, *numBoxes)
+		for i := range seats {
+			seats[i] = i
+		}
+		err = t.JoinBoxes(ui, f, seats...)
+	}
+	if err != nil {
+		handleError(err)
+	}
+	return t, f, false
+}
+
+// resumeSession resumes the session saved in file name. The boxes of the
+// player are at the first seats, like the boxes of a new session.
+func resumeSession(ui *textUI, name string) (*blackjack.Table, *player.Fortune, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	t, err := blackjack.ResumeTable(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var seats []int
+	for seat := 0; seat < blackjack.MaxSeats; seat++ {
+		if t.Rejoin(seat, ui) != nil {
+			break
+		}
+		seats = append(seats, seat)
+	}
+	if len(seats) == 0 {
+		return nil, nil, fmt.Errorf("no player in saved session %s", name)
+	}
+	if len(seats) > 1 {
+		if err := t.RejoinBoxes(ui, seats...); err != nil {
+			return nil, nil, err
+		}
+	}
+	return t, t.State().Fortune, nil
+}
+
+// saveSession saves the session at table t to file name. The session is
+// written to a temporary file first, so a saved session is never lost
+// halfway through saving.
+func saveSession(t *blackjack.Table, name string) error {
+	tmp := name + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := t.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// replay replays the hand history in file name with rules r, or with the
+// rules of the history when r is nil.
+func replay(ui *textUI, name string, r blackjack.Rules) {
+	f, err := os.Open(name)
+	if err != nil {
+		handleError(err)
+	}
+	defer f.Close()
 
-	f := player.NewFortune(decimal.New(50, 0))
-	blackjack.Play(ui, blackjack.HollandCasino, f)
+	hs, err := blackjack.ReadHistory(f)
+	if err != nil {
+		handleError(err)
+	}
+	if len(hs) == 0 {
+		ui.writeln("No rounds to replay.")
+		return
+	}
+	if r == nil {
+		if r, err = hs[0].GameRules(); err != nil {
+			handleError(err)
+		}
+	}
+
+	ui.writef("Replaying %d rounds with rules %s\n", len(hs), blackjack.RulesName(r))
+	err = blackjack.Replay(hs, r, ui)
+	var re *blackjack.ReplayError
+	if errors.As(err, &re) {
+		ui.writeln()
+		ui.writeln(re)
+		os.Exit(1)
+	}
+	if err != nil {
+		handleError(err)
+	}
+	ui.writeln()
+	ui.writeln("The replay matches the hand history.")
+}
+
+func presetNames() string {
+	var names []string
+	for _, r := range blackjack.AvailableRules {
+		names = append(names, blackjack.RulesName(r))
+	}
+	return strings.Join(names, ", ")
 }
 
 func handleError(err error) {
@@ -31,14 +301,23 @@ func handleError(err error) {
 }
 
 type textUI struct {
-	r   *bufio.Reader
-	w   io.Writer
-	err func(error)
-	bet decimal.Decimal
-	pp  decimal.Decimal
+	r    *bufio.Reader
+	w    io.Writer
+	err  func(error)
+	bet  decimal.Decimal
+	pp   decimal.Decimal
+	step bool // wait after a replayed move
+
+	// input, when set, is held by the player except while waiting for
+	// input.
+	input *sync.Mutex
 }
 
 func (ui *textUI) readString() string {
+	if ui.input != nil {
+		ui.input.Unlock()
+		defer ui.input.Lock()
+	}
 	s, err := ui.r.ReadString('\n')
 	if err != nil {
 		ui.err(err)
@@ -88,10 +367,10 @@ const noDef = '\000'
 
 func (ui *textUI) getRune(msg string, choices []string, accept []rune, def rune) rune {
 	if len(choices) == 0 {
-		panic("no choices provided")
+		ui.err(errors.New("no choices provided"))
 	}
 	if len(accept) == 0 {
-		panic("no accept runes provided")
+		ui.err(errors.New("no accept runes provided"))
 	}
 
 	for i, r := range accept {
@@ -161,6 +440,10 @@ func (ui *textUI) getDecimal(msg string, prev decimal.Decimal, must bool) decima
 			ui.writeln("error:", err)
 			continue
 		}
+		if d.Sign() < 0 {
+			ui.writeln("error: amount can't be negative")
+			continue
+		}
 
 		return d
 	}
@@ -171,6 +454,23 @@ func (ui *textUI) writeFortune(f *player.Fortune) {
 	ui.writef("Active: %v\tSavings: %v\tStake: %v\n", f.Active(), f.Savings(), f.Stake())
 }
 
+func (ui *textUI) writeSummary(s blackjack.Summary) {
+	ui.writeln()
+	ui.writef("Played %d rounds (%d hands) in %v.\n", s.Rounds, s.Hands, s.Duration.Round(time.Second))
+	ui.writef("Won: %d (blackjack: %d)\tLost: %d\tPushed: %d\tSurrendered: %d\n",
+		s.Won, s.Blackjacks, s.Lost, s.Pushed, s.Surrendered)
+	ui.writef("Wagered: %v\tNet: %v\n", s.Wagered, s.Net)
+	ui.writef("Biggest win: %v\tBiggest loss: %v\n", s.BiggestWin, s.BiggestLoss)
+	if s.PerfectPair.Bets > 0 {
+		ui.writef("Perfect pair: %d bets, %d won, net %v\n",
+			s.PerfectPair.Bets, s.PerfectPair.Won, s.PerfectPair.Net)
+	}
+	if s.Insurance.Bets > 0 {
+		ui.writef("Insurance: %d bets, %d won, net %v\n",
+			s.Insurance.Bets, s.Insurance.Won, s.Insurance.Net)
+	}
+}
+
 func (ui *textUI) Bet(f *player.Fortune) decimal.Decimal {
 	ui.writeFortune(f)
 
@@ -190,12 +490,61 @@ func (ui *textUI) Bet(f *player.Fortune) decimal.Decimal {
 	return amount
 }
 
-func (ui *textUI) Hand(d, p blackjack.Hand) {
+func (ui *textUI) BetRejected(err *blackjack.BetError) {
+	if err.Kind == blackjack.MainBet {
+		ui.bet = decimal.Zero
+	}
+	ui.writeln(err)
+}
+
+func (ui *textUI) Box(seat int) {
+	ui.writeln()
+	ui.writef("Box %d\n", seat+1)
+}
+
+func (ui *textUI) Replay(m blackjack.HistoryMove) {
+	ui.writeln()
+	ui.writef("Seat %d, %s: ", m.Seat+1, strings.TrimPrefix(m.Phase.String(), "Phase"))
+	switch m.Phase {
+	case blackjack.PhaseAction:
+		ui.write(m.Action)
+	case blackjack.PhaseNewGame, blackjack.PhaseEvenMoney:
+		if m.Accept {
+			ui.write("yes")
+		} else {
+			ui.write("no")
+		}
+	default:
+		ui.write(m.Amount)
+	}
+
+	if ui.step {
+		ui.write(" ")
+		ui.readString()
+	} else {
+		ui.writeln()
+	}
+}
+
+func (ui *textUI) Hand(d blackjack.DealerHand, p blackjack.Hand) {
 	ui.writeln()
 	ui.writeln("Dealer:", d)
 	ui.writeln("Player:", p)
 }
 
+func (ui *textUI) DealerPeek(bj bool) {
+	if bj {
+		ui.writeln("Dealer peeks: blackjack!")
+	} else {
+		ui.writeln("Dealer peeks: no blackjack")
+	}
+}
+
+func (ui *textUI) DealerReveal(h blackjack.Hand) {
+	ui.writeln()
+	ui.writeln("Dealer:", h)
+}
+
 func (ui *textUI) DealerCard(c card.Card, h blackjack.Hand) {
 	if len(h) == 2 {
 		ui.writeln()
@@ -229,14 +578,15 @@ func (ui *textUI) NextAction(actions []blackjack.Action) blackjack.Action {
 		r[i] = actionRunes[a]
 	}
 
-	ar := ui.getRune("What is your next action?", s, r, noDef)
-	for a, r := range actionRunes {
-		if r == ar {
-			return a
+	for {
+		ar := ui.getRune("What is your next action?", s, r, noDef)
+		for a, r := range actionRunes {
+			if r == ar {
+				return a
+			}
 		}
+		ui.writeln("invalid user input:", string(ar))
 	}
-
-	panic("invalid user input: " + string(ar))
 }
 
 func (ui *textUI) SplitHand(lh, rh blackjack.Hand, a decimal.Decimal) {
@@ -259,6 +609,15 @@ func (ui *textUI) Outcome(o blackjack.Outcome, a decimal.Decimal, d, p blackjack
 	ui.writeln("Amount: ", a)
 }
 
+func (ui *textUI) BonusOutcome(kind blackjack.BonusHand, a decimal.Decimal, d, p blackjack.Hand) {
+	ui.writeln()
+	ui.writeln("Outcome:", blackjack.Bonus)
+	ui.writeln("Bonus:  ", kind)
+	ui.writeln("Dealer: ", d)
+	ui.writeln("Player: ", p)
+	ui.writeln("Amount: ", a)
+}
+
 func (ui *textUI) NewGame(f *player.Fortune) bool {
 	ui.writeFortune(f)
 	choices := []string{"[Y]es", "[N]o"}
@@ -289,3 +648,22 @@ func (ui *textUI) PerfectPair(kind blackjack.PerfectPair, a decimal.Decimal) {
 	ui.writeln("Kind:  ", kind)
 	ui.writeln("Amount:", a)
 }
+
+func (ui *textUI) InsuranceBet(f *player.Fortune, max decimal.Decimal) decimal.Decimal {
+	msg := fmt.Sprintf("How much do you want to bet for insurance (max %v)?", max)
+	return ui.getDecimal(msg, decimal.Zero, false)
+}
+
+func (ui *textUI) Insurance(won bool, a decimal.Decimal) {
+	ui.writeln()
+	ui.writeln("Insurance")
+	ui.writeln("Won:   ", won)
+	ui.writeln("Amount:", a)
+}
+
+func (ui *textUI) TakeEvenMoney() bool {
+	choices := []string{"[Y]es", "[N]o"}
+	runes := []rune{'y', 'n'}
+	r := ui.getRune("Do you want to take even money?", choices, runes, 'n')
+	return r == 'y'
+}
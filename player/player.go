@@ -1,7 +1,11 @@
 // Package player provides card game player related data types.
 package player
 
-import "github.com/shopspring/decimal"
+import (
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+)
 
 // Fortune represents a player assets.
 type Fortune struct {
@@ -53,3 +57,25 @@ func (f *Fortune) Withdrawal(amount decimal.Decimal) {
 func (f *Fortune) Deposit(amount decimal.Decimal) {
 	f.active = f.active.Add(amount)
 }
+
+// fortuneJSON is the JSON encoding of a fortune.
+type fortuneJSON struct {
+	Stake   decimal.Decimal `json:"stake"`
+	Active  decimal.Decimal `json:"active"`
+	Savings decimal.Decimal `json:"savings"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f Fortune) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fortuneJSON{f.stake, f.active, f.saving})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *Fortune) UnmarshalJSON(data []byte) error {
+	var v fortuneJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	f.stake, f.active, f.saving = v.Stake, v.Active, v.Savings
+	return nil
+}
@@ -0,0 +1,205 @@
+package strategy
+
+// Cards are counted by their value: index 0 is an ace, index 9 is any
+// ten-valued card.
+const (
+	ace    = 0
+	ten    = 9
+	values = 10
+)
+
+// comp is the composition of a hand: the number of cards of every value.
+type comp [values]uint8
+
+func (c comp) add(v int) comp {
+	c[v]++
+	return c
+}
+
+// cards returns the number of cards in hand c.
+func (c comp) cards() int {
+	n := 0
+	for _, k := range c {
+		n += int(k)
+	}
+	return n
+}
+
+// hard returns the total of hand c with every ace counted as 1.
+func (c comp) hard() int {
+	t := 0
+	for v, k := range c {
+		t += (v + 1) * int(k)
+	}
+	return t
+}
+
+// total returns the best total of hand c and whether an ace is counted
+// as 11.
+func (c comp) total() (total int, soft bool) {
+	t := c.hard()
+	if c[ace] > 0 && t+10 <= 21 {
+		return t + 10, true
+	}
+	return t, false
+}
+
+// pair returns the value of the pair hand c is, or -1 if it isn't one.
+func (c comp) pair() int {
+	for v, k := range c {
+		if k == 2 && c.cards() == 2 {
+			return v
+		}
+	}
+	return -1
+}
+
+// shoe is the composition of the cards that are left to draw. An
+// infinite shoe draws every value with the odds of a full deck.
+type shoe struct {
+	n     [values]int
+	total int
+	inf   bool
+}
+
+func newShoe(decks uint) shoe {
+	if decks == Infinite {
+		return shoe{inf: true}
+	}
+	var s shoe
+	for v := range s.n {
+		s.n[v] = 4 * int(decks)
+	}
+	s.n[ten] *= 4
+	s.total = 52 * int(decks)
+	return s
+}
+
+// p returns the chance that the next card has value v.
+func (s *shoe) p(v int) float64 {
+	if s.inf {
+		if v == ten {
+			return 4.0 / 13
+		}
+		return 1.0 / 13
+	}
+	if s.total == 0 {
+		return 0
+	}
+	return float64(s.n[v]) / float64(s.total)
+}
+
+// has returns true if hand c can be drawn from the shoe.
+func (s shoe) has(c comp) bool {
+	if s.inf {
+		return true
+	}
+	for v, k := range c {
+		if int(k) > s.n[v] {
+			return false
+		}
+	}
+	return true
+}
+
+// without returns the shoe without the cards of hand c.
+func (s shoe) without(c comp) shoe {
+	if s.inf {
+		return s
+	}
+	for v, k := range c {
+		s.n[v] -= int(k)
+		s.total -= int(k)
+	}
+	return s
+}
+
+// take returns the shoe without a card of value v.
+func (s shoe) take(v int) shoe {
+	var c comp
+	return s.without(c.add(v))
+}
+
+// Final hands of the dealer.
+const (
+	dealer17 = iota
+	dealer18
+	dealer19
+	dealer20
+	dealer21
+	dealerBust
+	dealerBlackjack
+	dealerHands
+)
+
+// odds are the chances of the final hands of the dealer.
+type odds [dealerHands]float64
+
+// dealer calculates the odds of the final hand of a dealer by drawing
+// the cards from a shoe.
+type dealer struct {
+	shoe shoe
+	h17  bool
+	memo map[uint64]odds // by hand
+}
+
+// odds returns the odds of the final hand of the dealer with hand c, the
+// up-card and, if known, the hole card. A blackjack is only possible if
+// the second card is drawn.
+func (d *dealer) odds(c comp) odds {
+	var hand uint64
+	for v, k := range c {
+		hand += uint64(k) << (5 * v)
+	}
+	return d.draw(hand, c.hard(), c[ace] > 0, c.cards())
+}
+
+// draw returns the odds of a hand with hard total and n cards, that has
+// an ace if aces is true. The cards of the hand are counted in 5 bits a
+// value by hand.
+func (d *dealer) draw(hand uint64, hard int, aces bool, n int) odds {
+	total, soft := hard, false
+	if aces && hard+10 <= 21 {
+		total, soft = hard+10, true
+	}
+
+	var o odds
+	switch {
+	case n == 2 && total == 21:
+		o[dealerBlackjack] = 1
+		return o
+	case hard > 21:
+		o[dealerBust] = 1
+		return o
+	case total > 17 || total == 17 && !(soft && d.h17):
+		o[dealer17+total-17] = 1
+		return o
+	}
+
+	if o, ok := d.memo[hand]; ok {
+		return o
+	}
+	inv := 1 / float64(d.shoe.total)
+	for v := 0; v < values; v++ {
+		var p float64
+		if d.shoe.inf {
+			p = d.shoe.p(v)
+		} else if d.shoe.n[v] == 0 {
+			continue
+		} else {
+			p = float64(d.shoe.n[v]) * inv
+			d.shoe.n[v]--
+			d.shoe.total--
+		}
+		next := d.draw(hand+1<<(5*v), hard+v+1, aces || v == ace, n+1)
+		if !d.shoe.inf {
+			d.shoe.n[v]++
+			d.shoe.total++
+		}
+		for i := range o {
+			o[i] += p * next[i]
+		}
+	}
+	d.memo[hand] = o
+	return o
+}
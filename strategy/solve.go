@@ -0,0 +1,889 @@
+package strategy
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dwlnetnl/cards/blackjack"
+
+	"github.com/shopspring/decimal"
+)
+
+// unlimitedHands caps the number of hands that are valued when the rules
+// don't limit splitting, more hands hardly change the value of a split.
+const unlimitedHands = 16
+
+// maxIterations caps the iterations of finding the strategy of hands
+// with three or more cards.
+const maxIterations = 8
+
+// ev is the expected value of a hand per unit bet. A dealer blackjack that
+// is found after the player played takes all bets or the original bet
+// only, so that part is kept apart.
+type ev struct {
+	win float64 // value when the dealer has no blackjack
+	bj  float64 // chance of a dealer blackjack found after play
+	bet float64 // bets lost against it
+}
+
+func (e ev) add(f ev) ev { return ev{e.win + f.win, e.bj + f.bj, e.bet + f.bet} }
+
+func (e ev) scale(p float64) ev { return ev{p * e.win, p * e.bj, p * e.bet} }
+
+// payout is a blackjack.HandPayout with a float ratio.
+type payout struct {
+	cards, total int
+	suited       bool
+	ratio        float64
+}
+
+// solver has the rules of a game as the numbers needed to value hands.
+type solver struct {
+	rules blackjack.Rules
+	decks uint
+	cards int // hands with more cards are valued like this number of cards
+
+	h17, tie, obo              bool
+	blackjack, suitedBlackjack float64
+	win, surrender             float64
+	hands                      []payout
+
+	charlie    int
+	bonusRatio [4]float64 // Charlie, suited 6-7-8, 7-7-7, five card 21
+}
+
+func float(d decimal.Decimal) float64 {
+	f, _ := d.Float64()
+	return f
+}
+
+func orDefault(d decimal.Decimal, def float64) float64 {
+	if d.IsZero() {
+		return def
+	}
+	return float(d)
+}
+
+func newSolver(r blackjack.Rules, decks uint) *solver {
+	p, b := r.Payouts(), r.Bonuses()
+	s := &solver{
+		rules:           r,
+		decks:           decks,
+		cards:           3,
+		h17:             r.DealerHitSoft17(),
+		tie:             r.DealerWinsTie(),
+		obo:             r.OriginalBetsOnly(),
+		blackjack:       float(r.BlackjackRatio()),
+		suitedBlackjack: float(p.SuitedBlackjack),
+		win:             orDefault(p.Win, 1),
+		surrender:       orDefault(p.Surrender, 0.5),
+		charlie:         b.Charlie,
+	}
+	s.bonusRatio = [4]float64{orDefault(b.CharlieRatio, 1), float(b.Suited678), float(b.Triple7), float(b.FiveCard21)}
+
+	// Hands are told apart by their number of cards as far as it matters
+	// for a payout or bonus.
+	more := func(n int) {
+		if n > s.cards {
+			s.cards = n
+		}
+	}
+	for _, hp := range p.Hands {
+		s.hands = append(s.hands, payout{hp.Cards, hp.Total, hp.Suited, float(hp.Ratio)})
+		more(hp.Cards)
+		if hp.Suited {
+			more(12) // the odds of a suited hand drop with every card
+		}
+	}
+	if s.charlie > 0 {
+		more(s.charlie)
+	}
+	if s.bonusRatio[1] > 0 || s.bonusRatio[2] > 0 {
+		more(4)
+	}
+	if s.bonusRatio[3] > 0 {
+		more(5)
+	}
+	return s
+}
+
+// suited returns the chance that the cards of hand c are of one suit.
+// The suits of the cards that are drawn before are not taken into account.
+func (s *solver) suited(c comp) float64 {
+	if s.decks == Infinite {
+		return math.Pow(0.25, float64(c.cards()-1))
+	}
+	p := 4.0
+	for v, k := range c {
+		m := int(s.decks) // cards of a value and suit
+		if v == ten {
+			m *= 4
+		}
+		for j := 0; j < int(k); j++ {
+			p *= float64(m-j) / float64(4*m-j)
+		}
+	}
+	return p
+}
+
+// winRatio returns the expected win ratio of winning hand c.
+func (s *solver) winRatio(c comp) float64 {
+	n := c.cards()
+	t, _ := c.total()
+	ratio, suited := s.win, s.win
+	for _, hp := range s.hands {
+		if n < hp.cards || hp.total > 0 && t != hp.total {
+			continue
+		}
+		if !hp.suited {
+			ratio = math.Max(ratio, hp.ratio)
+		}
+		suited = math.Max(suited, hp.ratio)
+	}
+	if suited > ratio {
+		ps := s.suited(c)
+		return (1-ps)*ratio + ps*suited
+	}
+	return ratio
+}
+
+// blackjackRatio returns the expected ratio paid for blackjack c.
+func (s *solver) blackjackRatio(c comp) float64 {
+	if s.suitedBlackjack == 0 {
+		return s.blackjack
+	}
+	ps := s.suited(c)
+	return (1-ps)*s.blackjack + ps*s.suitedBlackjack
+}
+
+// bonus returns the chance that hand c is a bonus hand and its expected
+// ratio then, like blackjack.Bonuses.Match.
+func (s *solver) bonus(c comp) (p, ratio float64) {
+	n := c.cards()
+	t, _ := c.total()
+	if c.hard() > 21 {
+		return 0, 0
+	}
+
+	if s.charlie > 0 && n >= s.charlie {
+		ratio = s.bonusRatio[0]
+	}
+	if n == 3 && c[6] == 3 {
+		ratio = math.Max(ratio, s.bonusRatio[2])
+	}
+	if n >= 5 && t == 21 {
+		ratio = math.Max(ratio, s.bonusRatio[3])
+	}
+
+	suited := 0.0
+	if n == 3 && c[5] == 1 && c[6] == 1 && c[7] == 1 {
+		suited = s.bonusRatio[1]
+	}
+	switch {
+	case suited > ratio && ratio > 0:
+		ps := s.suited(c)
+		return 1, (1-ps)*ratio + ps*suited
+	case ratio > 0:
+		return 1, ratio
+	case suited > 0:
+		return s.suited(c), suited
+	}
+	return 0, 0
+}
+
+// canDouble returns true if a two card hand with total t can be doubled.
+func (s *solver) canDouble(t int, split, aces bool) bool {
+	switch s.rules.Double() {
+	case blackjack.DoubleOnly9_10_11:
+		if t < 9 || t > 11 {
+			return false
+		}
+	case blackjack.DoubleOnly10_11:
+		if t < 10 || t > 11 {
+			return false
+		}
+	}
+	if aces && !s.rules.DoubleSplitAces() {
+		return false
+	}
+	return !split || s.rules.DoubleAfterSplit()
+}
+
+// key returns the key of hand c in a chart.
+func (s *solver) key(c comp) key { return newKey(c, s.cards) }
+
+// upCard values the hands of the player against an up-card of the dealer.
+type upCard struct {
+	*solver
+	up      int
+	base    shoe  // cards left after the up-card and the cards of split
+	removed comp  // cards of a split that are not in the hands
+	holes   []int // hole cards without a blackjack if the dealer peeked, else -1
+	late    bool  // a dealer blackjack is found after the player played
+	chart   chart
+	firsts  map[comp]*twoCard // values of the first actions on two card hands
+
+	dec    map[key]bool // whether to hit hands with three or more cards
+	values map[valueKey]ev
+	odds   map[valueKey]odds
+	dealer *dealer // of an infinite shoe
+	finite *dealer // of the shoe of a hand
+}
+
+type valueKey struct {
+	c    comp
+	hole int
+}
+
+func (s *solver) upCard(up int) *upCard {
+	r := s.rules
+	x := &upCard{
+		solver: s,
+		up:     up,
+		base:   newShoe(s.decks).take(up),
+		holes:  []int{-1},
+		chart:  make(chart),
+		firsts: make(map[comp]*twoCard),
+		dec:    make(map[key]bool),
+		values: make(map[valueKey]ev),
+		odds:   make(map[valueKey]odds),
+		dealer: &dealer{h17: s.h17, shoe: shoe{inf: true}, memo: make(map[uint64]odds)},
+		finite: &dealer{h17: s.h17, memo: make(map[uint64]odds)},
+	}
+
+	canBlackjack := up == ace || up == ten
+	peek := r.Peek() == blackjack.PeekAceTen && canBlackjack || r.Peek() == blackjack.PeekAce && up == ace
+	if canBlackjack && peek && !r.NoHoleCard() {
+		x.holes = nil
+		for v := 0; v < values; v++ {
+			if v != x.blackjackCard() {
+				x.holes = append(x.holes, v)
+			}
+		}
+	} else {
+		x.late = canBlackjack
+	}
+	return x
+}
+
+// split returns the up-card to value the hands of a split of value v.
+func (x *upCard) split(v int) *upCard {
+	y := *x
+	y.base = x.base.take(v)
+	y.removed = x.removed.add(v)
+	y.values = make(map[valueKey]ev)
+	return &y
+}
+
+// peeked returns true if the dealer checked for blackjack.
+func (x *upCard) peeked() bool { return x.holes[0] >= 0 }
+
+// blackjackCard returns the value the dealer needs for blackjack.
+func (x *upCard) blackjackCard() int {
+	if x.up == ace {
+		return ten
+	}
+	return ace
+}
+
+// canSurrender returns true if hands can surrender against the up-card.
+func (x *upCard) canSurrender() bool {
+	switch x.rules.SurrenderUpCard() {
+	case blackjack.SurrenderNotAce:
+		if x.up == ace {
+			return false
+		}
+	case blackjack.SurrenderNotAceTen:
+		if x.up == ace || x.up == ten {
+			return false
+		}
+	}
+	switch x.rules.Surrender() {
+	case blackjack.EarlySurrender:
+		return true
+	case blackjack.LateSurrender:
+		return !x.late
+	}
+	return false
+}
+
+// holeOdds returns the chances of the hole cards of the dealer when the
+// player has hand c.
+func (x *upCard) holeOdds(c comp) []float64 {
+	if !x.peeked() {
+		return []float64{1}
+	}
+	sh := x.base.without(c)
+	p := make([]float64, len(x.holes))
+	sum := 0.0
+	for i, h := range x.holes {
+		p[i] = sh.p(h)
+		sum += p[i]
+	}
+	for i := range p {
+		p[i] /= sum
+	}
+	return p
+}
+
+// shoe returns the cards left to draw when the player has hand c and the
+// dealer hole card i.
+func (x *upCard) shoe(c comp, i int) shoe {
+	sh := x.base.without(c)
+	if h := x.holes[i]; h >= 0 {
+		sh = sh.take(h)
+	}
+	return sh
+}
+
+// blackjackOdds returns the chance that the dealer has a blackjack, that
+// is found after the player played hand c.
+func (x *upCard) blackjackOdds(c comp, i int) float64 {
+	if !x.late {
+		return 0
+	}
+	sh := x.shoe(c, i)
+	return sh.p(x.blackjackCard())
+}
+
+// peekOdds returns the chance that the dealer peeked and has blackjack
+// when the player has hand c.
+func (x *upCard) peekOdds(c comp) float64 {
+	if !x.peeked() {
+		return 0
+	}
+	sh := x.base.without(c)
+	return sh.p(x.blackjackCard())
+}
+
+// dealerOdds returns the odds of the final hand of the dealer when the
+// player has hand c and the dealer hole card i.
+func (x *upCard) dealerOdds(c comp, i int) odds {
+	// The odds are shared with the hands of a split that have the same
+	// cards left.
+	k := valueKey{c, i}
+	for v, n := range x.removed {
+		k.c[v] += n
+	}
+	if x.decks == Infinite {
+		k.c = comp{}
+	}
+	if o, ok := x.odds[k]; ok {
+		return o
+	}
+
+	var hand comp
+	hand = hand.add(x.up)
+	if h := x.holes[i]; h >= 0 {
+		hand = hand.add(h)
+	}
+	d := x.dealer
+	if x.decks != Infinite {
+		d = x.finite
+		d.shoe = x.shoe(c, i)
+		clear(d.memo)
+	}
+	o := d.odds(hand)
+	x.odds[k] = o
+	return o
+}
+
+// stand returns the value of standing on hand c with stake.
+func (x *upCard) stand(c comp, i int, stake float64) ev {
+	o := x.dealerOdds(c, i)
+	t, _ := c.total()
+	w := x.winRatio(c)
+
+	e := ev{win: o[dealerBust] * w * stake}
+	for d := dealer17; d <= dealer21; d++ {
+		switch dt := 17 + d - dealer17; {
+		case t > dt:
+			e.win += o[d] * w * stake
+		case t < dt || x.tie:
+			e.win -= o[d] * stake
+		}
+	}
+	e.bj = o[dealerBlackjack]
+	e.bet = e.bj * stake
+	return e
+}
+
+// lose returns the value of a hand with stake that is lost, unless the
+// dealer has blackjack which settles it.
+func (x *upCard) lose(c comp, i int, stake, ratio float64) ev {
+	pb := x.blackjackOdds(c, i)
+	return ev{win: ratio * stake * (1 - pb), bj: pb, bet: stake * pb}
+}
+
+// done returns the value of hand c with stake that is done: a bust, a
+// bonus hand or a hand that stands.
+func (x *upCard) done(c comp, i int, stake float64) ev {
+	if c.hard() > 21 {
+		return x.lose(c, i, stake, -1)
+	}
+	p, ratio := x.bonus(c)
+	var e ev
+	if p < 1 {
+		e = x.stand(c, i, stake).scale(1 - p)
+	}
+	if p > 0 {
+		b := ev{win: ratio * stake}
+		if x.late {
+			b = x.lose(c, i, stake, ratio)
+		}
+		e = e.add(b.scale(p))
+	}
+	return e
+}
+
+// value returns the value of hand c with three or more cards, played by
+// the strategy.
+func (x *upCard) value(c comp, i int) ev {
+	k := valueKey{c, i}
+	if e, ok := x.values[k]; ok {
+		return e
+	}
+
+	var e ev
+	if x.decides(c) && x.dec[x.key(c)] {
+		e = x.hit(c, i)
+	} else {
+		e = x.done(c, i, 1)
+	}
+	x.values[k] = e
+	return e
+}
+
+// decides returns true if the player has a choice with hand c, it is not
+// bust, 21 or a bonus hand.
+func (x *upCard) decides(c comp) bool {
+	t, _ := c.total()
+	if c.hard() > 21 || t == 21 {
+		return false
+	}
+	p, _ := x.bonus(c)
+	return p < 1
+}
+
+// hit returns the value of hitting hand c.
+func (x *upCard) hit(c comp, i int) ev {
+	sh := x.shoe(c, i)
+	var e ev
+	for v := 0; v < values; v++ {
+		if p := sh.p(v); p > 0 {
+			e = e.add(x.value(c.add(v), i).scale(p))
+		}
+	}
+	return e
+}
+
+// double returns the value of doubling hand c.
+func (x *upCard) double(c comp, i int) ev {
+	sh := x.shoe(c, i)
+	var e ev
+	for v := 0; v < values; v++ {
+		if p := sh.p(v); p > 0 {
+			e = e.add(x.done(c.add(v), i, 2).scale(p))
+		}
+	}
+	return e
+}
+
+func (x *upCard) total(e ev) float64 {
+	if x.obo {
+		return e.win - e.bj
+	}
+	return e.win - e.bet
+}
+
+// expect returns the value of taking action a on hand c over the hole
+// cards of the dealer.
+func (x *upCard) expect(c comp, a func(c comp, i int) ev) float64 {
+	sum := 0.0
+	for i, p := range x.holeOdds(c) {
+		sum += p * x.total(a(c, i))
+	}
+	return sum
+}
+
+// deal returns the chance that the player is dealt two card hand c.
+func (x *upCard) deal(c comp) float64 {
+	p := 1.0
+	sh := x.base
+	for v, k := range c {
+		for j := 0; j < int(k); j++ {
+			p *= sh.p(v)
+			sh = sh.take(v)
+		}
+	}
+	if c.pair() < 0 {
+		p *= 2
+	}
+	return p
+}
+
+// hands returns the hands of the player that can be drawn, by number of
+// cards.
+func (x *upCard) hands() [][]comp {
+	var byCards [][]comp
+	var walk func(c comp, from, n int)
+	walk = func(c comp, from, n int) {
+		if n >= 2 {
+			for len(byCards) <= n {
+				byCards = append(byCards, nil)
+			}
+			byCards[n] = append(byCards[n], c)
+		}
+		for v := from; v < values; v++ {
+			next := c.add(v)
+			if next.hard() <= 21 && x.base.has(next) {
+				walk(next, v, n+1)
+			}
+		}
+	}
+	walk(comp{}, 0, 0)
+	return byCards
+}
+
+// order returns the order in which the hand with key k is decided: every
+// hand that can be drawn to is decided before.
+func order(k key) int {
+	switch {
+	case k.kind == softHand:
+		return 100 + 21 - k.total
+	case k.total >= 12:
+		return 21 - k.total
+	}
+	return 200 + 21 - k.total
+}
+
+// solve computes the chart of the up-card and returns the expected value
+// of a round.
+func (x *upCard) solve() float64 {
+	byCards := x.hands()
+	two := byCards[2]
+
+	// The hands with three or more cards that need a decision, by key.
+	groups := make(map[key][]comp)
+	var keys []key
+	for _, cs := range byCards[3:] {
+		for _, c := range cs {
+			if !x.decides(c) {
+				continue
+			}
+			k := x.key(c)
+			if _, ok := groups[k]; !ok {
+				keys = append(keys, k)
+			}
+			groups[k] = append(groups[k], c)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if oi, oj := order(keys[i]), order(keys[j]); oi != oj {
+			return oi < oj
+		}
+		return keys[i].cards < keys[j].cards
+	})
+
+	// Start like a dealer and find the strategy for hands of three or more
+	// cards, weighting every hand by the chance to get it by the strategy.
+	hits := make(map[comp]bool)
+	for _, cs := range byCards[2:] {
+		for _, c := range cs {
+			t, soft := c.total()
+			hits[c] = t < 17 || soft && t < 18
+		}
+	}
+	for _, k := range keys {
+		x.dec[k] = hits[groups[k][0]]
+	}
+	all := x.reach(byCards, func(c comp) bool { return x.decides(c) })
+
+	for it := 0; it < maxIterations; it++ {
+		w := x.reach(byCards, func(c comp) bool { return x.decides(c) && hits[c] })
+		x.values = make(map[valueKey]ev)
+
+		changed := false
+		for _, k := range keys {
+			var sum, hit, stand float64
+			weights := w
+			for _, c := range groups[k] {
+				sum += w[c]
+			}
+			if sum == 0 {
+				weights = all
+			}
+			sum = 0
+			for _, c := range groups[k] {
+				p := weights[c]
+				if p == 0 {
+					continue
+				}
+				sum += p
+				hit += p * x.expect(c, x.hit)
+				stand += p * x.expect(c, func(c comp, i int) ev { return x.done(c, i, 1) })
+			}
+			if sum > 0 {
+				hit, stand = hit/sum, stand/sum
+			}
+			h := hit > stand
+			changed = changed || h != x.dec[k]
+			x.dec[k] = h
+			e := x.chart.entry(k)
+			e.set(blackjack.Hit, hit)
+			e.set(blackjack.Stand, stand)
+			for _, c := range groups[k] {
+				hits[c] = h
+			}
+		}
+
+		for c, h := range x.decideTwo(two, false) {
+			changed = changed || h != hits[c]
+			hits[c] = h
+		}
+		if !changed && it > 0 {
+			break
+		}
+	}
+	x.decideTwo(two, true)
+
+	// The value of a round against the up-card.
+	sum := 0.0
+	for _, c := range two {
+		sum += x.deal(c) * x.round(c)
+	}
+	return sum
+}
+
+// reach returns the chances to get the hands, drawing to the two card
+// hands for which hit returns true.
+func (x *upCard) reach(byCards [][]comp, hit func(c comp) bool) map[comp]float64 {
+	w := make(map[comp]float64)
+	for _, c := range byCards[2] {
+		w[c] = x.deal(c)
+	}
+	for _, cs := range byCards[3:] {
+		for _, c := range cs {
+			p := 0.0
+			for v := 0; v < values; v++ {
+				if c[v] == 0 {
+					continue
+				}
+				prev := c
+				prev[v]--
+				if w[prev] > 0 && hit(prev) {
+					sh := x.base.without(prev)
+					p += w[prev] * sh.p(v)
+				}
+			}
+			w[c] = p
+		}
+	}
+	return w
+}
+
+// twoCard has the values of the actions on a two card hand.
+type twoCard struct {
+	c    comp
+	p    float64 // chance to be dealt
+	acts map[blackjack.Action]float64
+}
+
+// decideTwo computes the chart entries of two card hands and returns
+// whether each hand hits. Splits and the round values are only computed
+// when final is true.
+func (x *upCard) decideTwo(two []comp, final bool) map[comp]bool {
+	// Pairs are valued after the other hands, a split plays those.
+	sorted := append([]comp(nil), two...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].pair() < 0 && sorted[j].pair() >= 0
+	})
+
+	byKey := make(map[key][]*twoCard)
+	var keys []key
+	for _, c := range sorted {
+		if t, _ := c.total(); t == 21 {
+			continue // blackjack
+		}
+		tc := &twoCard{c: c, p: x.deal(c), acts: make(map[blackjack.Action]float64)}
+		tc.acts[blackjack.Hit] = x.expect(c, x.hit)
+		tc.acts[blackjack.Stand] = x.expect(c, func(c comp, i int) ev { return x.done(c, i, 1) })
+		if t, _ := c.total(); x.canDouble(t, false, false) {
+			tc.acts[blackjack.Double] = x.expect(c, x.double)
+		}
+		if x.canSurrender() {
+			tc.acts[blackjack.Surrender] = x.surrender - 1
+		}
+
+		k := x.key(c)
+		if _, ok := byKey[k]; !ok {
+			keys = append(keys, k)
+		}
+		byKey[k] = append(byKey[k], tc)
+	}
+
+	hits := make(map[comp]bool)
+	for _, k := range keys {
+		tcs := byKey[k]
+		e := x.chart.entry(k)
+		*e = entry{}
+		x.average(e, tcs)
+
+		if final && k.kind == pair {
+			for _, tc := range tcs {
+				tc.acts[blackjack.Split] = x.expect(tc.c, x.splitter(k.total))
+			}
+			x.average(e, tcs)
+		}
+		if final && x.rules.Surrender() == blackjack.EarlySurrender && x.canSurrender() {
+			best, _ := e.best(blackjack.Hit, blackjack.Stand, blackjack.Double, blackjack.Split)
+			for _, tc := range tcs {
+				pb := x.peekOdds(tc.c)
+				tc.acts[blackjack.Continue] = (1-pb)*tc.acts[best] - pb
+			}
+			x.average(e, tcs)
+		}
+
+		best, _ := e.best(blackjack.Hit, blackjack.Stand, blackjack.Double, blackjack.Split, blackjack.Surrender)
+		for _, tc := range tcs {
+			hits[tc.c] = best == blackjack.Hit
+		}
+		if final {
+			for _, tc := range tcs {
+				x.firsts[tc.c] = tc
+			}
+		}
+	}
+	return hits
+}
+
+// average sets the values of entry e to the average of the values of
+// the hands, weighted by the chances to be dealt them.
+func (x *upCard) average(e *entry, tcs []*twoCard) {
+	sum := 0.0
+	for _, tc := range tcs {
+		sum += tc.p
+	}
+	for a := range tcs[0].acts {
+		v := 0.0
+		for _, tc := range tcs {
+			v += tc.p * tc.acts[a]
+		}
+		if sum > 0 {
+			v /= sum
+		}
+		e.set(a, v)
+	}
+}
+
+// round returns the value of a round in which the player is dealt c.
+func (x *upCard) round(c comp) float64 {
+	if t, _ := c.total(); t == 21 {
+		r := x.blackjackRatio(c)
+		if x.peeked() {
+			return (1 - x.peekOdds(c)) * r
+		}
+		return (1 - x.blackjackOdds(c, 0)) * r
+	}
+
+	tc := x.firsts[c]
+	e := x.chart[x.key(c)]
+	if _, ok := tc.acts[blackjack.Continue]; ok {
+		a, _ := e.best(blackjack.Surrender, blackjack.Continue)
+		return tc.acts[a]
+	}
+	a, _ := e.best(blackjack.Hit, blackjack.Stand, blackjack.Double, blackjack.Split, blackjack.Surrender)
+	pb := x.peekOdds(c)
+	return (1-pb)*tc.acts[a] - pb
+}
+
+// splitter returns the value of splitting a pair of value v.
+func (x *upCard) splitter(v int) func(c comp, i int) ev {
+	y := x.split(v)
+	aces := v == ace
+	max := x.rules.SplitHands()
+	if max == 0 {
+		max = unlimitedHands
+	}
+	resplit := !aces || x.rules.ResplitAces()
+
+	return func(c comp, i int) ev {
+		var one comp
+		one = one.add(v)
+		sh := y.shoe(one, i)
+
+		// Every split hand gets a card, a pair that can't be split again is
+		// played like the other hands.
+		var all ev
+		for w := 0; w < values; w++ {
+			if p := sh.p(w); p > 0 {
+				all = all.add(y.post(one.add(w), i, aces).scale(p))
+			}
+		}
+		q := 0.0
+		if resplit {
+			q = sh.p(v)
+			if v == ten && !x.rules.SplitUnequalTens() {
+				q /= 4 // the same rank
+			}
+		}
+		pair := y.post(one.add(v), i, aces)
+		other := all.add(pair.scale(-q))
+
+		// f values the pending hands that get a card when the split has a
+		// number of hands.
+		memo := make(map[[2]int]ev)
+		var f func(pending, hands int) ev
+		f = func(pending, hands int) ev {
+			if pending == 0 {
+				return ev{}
+			}
+			k := [2]int{pending, hands}
+			if e, ok := memo[k]; ok {
+				return e
+			}
+			e := other.add(f(pending-1, hands).scale(1 - q))
+			if hands < max {
+				e = e.add(f(pending+1, hands+1).scale(q))
+			} else {
+				e = e.add(pair.add(f(pending-1, hands)).scale(q))
+			}
+			memo[k] = e
+			return e
+		}
+
+		e := f(2, 2)
+		e.bj = x.blackjackOdds(c, i) // only the original bet, if so
+		return e
+	}
+}
+
+// post returns the value of two card hand c after a split.
+func (x *upCard) post(c comp, i int, aces bool) ev {
+	t, _ := c.total()
+	if t == 21 {
+		if x.rules.BlackjackAfterSplit() {
+			return ev{win: x.blackjackRatio(c) * (1 - x.blackjackOdds(c, i))}
+		}
+		return x.done(c, i, 1)
+	}
+
+	allowed := []blackjack.Action{blackjack.Hit, blackjack.Stand}
+	if aces && !x.rules.HitSplitAces() {
+		allowed = allowed[1:]
+	}
+	if x.canDouble(t, true, aces) {
+		allowed = append(allowed, blackjack.Double)
+	}
+	a := blackjack.Stand
+	if e := x.chart[x.key(c)]; e != nil {
+		a, _ = e.best(allowed...)
+	}
+	switch a {
+	case blackjack.Hit:
+		return x.hit(c, i)
+	case blackjack.Double:
+		return x.double(c, i)
+	}
+	return x.done(c, i, 1)
+}
@@ -0,0 +1,323 @@
+// Package strategy computes the basic strategy of blackjack rules.
+//
+// The strategy is total-dependent: it decides by the total of a hand, if
+// it is soft or a pair, and against the up-card of the dealer. It is found
+// by the exact expected values of the actions, for an infinite shoe or for
+// a shoe of a number of decks of which the cards of the hand and the
+// up-card are removed. The hands of one total are weighted by the chance
+// to get them by the strategy. The hands of a split are valued without
+// the cards drawn to the other hands of the split.
+//
+// Insurance and even money are not part of the strategy, they are not
+// worth taking without counting cards.
+package strategy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dwlnetnl/cards/blackjack"
+	"github.com/dwlnetnl/cards/card"
+)
+
+// Infinite is the number of decks of an infinite shoe, which draws every
+// card with the odds of a full deck.
+const Infinite uint = 0
+
+// kind is the kind of hand in a chart.
+type kind int
+
+const (
+	hard kind = iota
+	softHand
+	pair
+)
+
+// key is a hand in a chart: a hard or soft total with a number of cards,
+// or a pair of a value. Two card hands can take every action, hands with
+// more cards only hit or stand.
+type key struct {
+	kind  kind
+	total int // value of a pair
+	cards int
+}
+
+// newKey returns the key of hand c, hands with more than cards are the
+// same as hands with cards.
+func newKey(c comp, cards int) key {
+	n := c.cards()
+	if v := c.pair(); v >= 0 {
+		return key{pair, v, 2}
+	}
+	t, soft := c.total()
+	k := key{hard, t, n}
+	if soft {
+		k.kind = softHand
+	}
+	if n > cards {
+		k.cards = cards
+	}
+	return k
+}
+
+// entry has the expected values of the actions on a hand in a chart.
+type entry struct {
+	ev [blackjack.Continue + 1]float64
+	ok [blackjack.Continue + 1]bool
+}
+
+func (e *entry) set(a blackjack.Action, v float64) {
+	e.ev[a], e.ok[a] = v, true
+}
+
+// best returns the action with the highest expected value of actions,
+// ok is false if none has a value.
+func (e *entry) best(actions ...blackjack.Action) (best blackjack.Action, ok bool) {
+	for _, a := range actions {
+		if a < 0 || int(a) >= len(e.ok) || !e.ok[a] {
+			continue
+		}
+		if !ok || e.ev[a] > e.ev[best] {
+			best, ok = a, true
+		}
+	}
+	return best, ok
+}
+
+// code returns the action for entry e in the notation of strategy
+// charts: the best action and what to do if it is not allowed.
+func (e *entry) code() string {
+	a, ok := e.best(blackjack.Hit, blackjack.Stand, blackjack.Double, blackjack.Split)
+	if !ok {
+		return "-"
+	}
+	if e.ok[blackjack.Surrender] {
+		other := e.ev[a]
+		if e.ok[blackjack.Continue] {
+			other = e.ev[blackjack.Continue]
+		}
+		if e.ev[blackjack.Surrender] > other {
+			if a == blackjack.Split {
+				return "Rp"
+			}
+			a, _ = e.best(blackjack.Hit, blackjack.Stand)
+			return "R" + strings.ToLower(e.codeOf(a))
+		}
+	}
+	if a == blackjack.Double {
+		if a, _ = e.best(blackjack.Hit, blackjack.Stand); a == blackjack.Stand {
+			return "Ds"
+		}
+		return "D"
+	}
+	return e.codeOf(a)
+}
+
+func (e *entry) codeOf(a blackjack.Action) string {
+	switch a {
+	case blackjack.Hit:
+		return "H"
+	case blackjack.Stand:
+		return "S"
+	case blackjack.Split:
+		return "P"
+	}
+	return "D"
+}
+
+// chart has the entries of the hands against an up-card.
+type chart map[key]*entry
+
+func (c chart) entry(k key) *entry {
+	e := c[k]
+	if e == nil {
+		e = new(entry)
+		c[k] = e
+	}
+	return e
+}
+
+// Strategy is the basic strategy of blackjack rules.
+type Strategy struct {
+	rules  blackjack.Rules
+	decks  uint
+	cards  int
+	ev     float64
+	charts [values]chart // by up-card
+}
+
+// New returns the strategy of rules r for the number of decks of the
+// rules.
+func New(r blackjack.Rules) *Strategy {
+	return NewDecks(r, r.NumDecks())
+}
+
+// NewDecks returns the strategy of rules r for a shoe of decks, which can
+// be Infinite.
+func NewDecks(r blackjack.Rules, decks uint) *Strategy {
+	sv := newSolver(r, decks)
+	s := &Strategy{rules: r, decks: decks, cards: sv.cards}
+
+	var evs [values]float64
+	var wg sync.WaitGroup
+	for up := 0; up < values; up++ {
+		wg.Add(1)
+		go func(up int) {
+			defer wg.Done()
+			x := sv.upCard(up)
+			evs[up] = x.solve()
+			s.charts[up] = x.chart
+		}(up)
+	}
+	wg.Wait()
+
+	full := newShoe(decks)
+	for up, ev := range evs {
+		s.ev += full.p(up) * ev
+	}
+	return s
+}
+
+// Rules returns the rules of strategy s.
+func (s *Strategy) Rules() blackjack.Rules { return s.rules }
+
+// Decks returns the number of decks strategy s is computed for.
+func (s *Strategy) Decks() uint { return s.decks }
+
+// EV returns the expected value of a round per unit bet, played by
+// strategy s from a full shoe. A negative value is the house edge.
+func (s *Strategy) EV() float64 { return s.ev }
+
+// value returns the value of card c as counted by the strategy, or -1 if
+// it has none.
+func value(c card.Card) int {
+	if c.Rank == card.Ace {
+		return ace
+	}
+	return card.BlackjackPoints.Points(c) - 1
+}
+
+func (s *Strategy) entry(h blackjack.Hand, up card.Card) *entry {
+	u := value(up)
+	if u < 0 || u >= values {
+		return nil
+	}
+	var c comp
+	for _, cd := range h {
+		v := value(cd)
+		if v < 0 || v >= values {
+			return nil
+		}
+		c = c.add(v)
+	}
+	if c.cards() < 2 || c.hard() > 21 {
+		return nil
+	}
+	return s.charts[u][newKey(c, s.cards)]
+}
+
+// Action returns the best of the allowed actions on hand h against
+// up-card up, like the legal actions of a table.
+func (s *Strategy) Action(h blackjack.Hand, up card.Card, allowed []blackjack.Action) blackjack.Action {
+	if e := s.entry(h, up); e != nil {
+		if a, ok := e.best(allowed...); ok {
+			return a
+		}
+	}
+	// A hand without a choice, like 21.
+	for _, a := range allowed {
+		if a == blackjack.Stand || a == blackjack.Continue {
+			return a
+		}
+	}
+	if len(allowed) > 0 {
+		return allowed[0]
+	}
+	return blackjack.Stand
+}
+
+// Values returns the expected values per unit bet of the actions on hand
+// h against up-card up. The values of a hand of a total are averaged over
+// the hands of that total. After the dealer peeked they are the values
+// without a dealer blackjack, except the value of Continue that decides
+// an early surrender before the dealer peeks.
+func (s *Strategy) Values(h blackjack.Hand, up card.Card) map[blackjack.Action]float64 {
+	e := s.entry(h, up)
+	if e == nil {
+		return nil
+	}
+	values := make(map[blackjack.Action]float64)
+	for a, ok := range e.ok {
+		if ok {
+			values[blackjack.Action(a)] = e.ev[a]
+		}
+	}
+	return values
+}
+
+func valueName(v int) string {
+	switch v {
+	case ace:
+		return "A"
+	case ten:
+		return "T"
+	}
+	return strconv.Itoa(v + 1)
+}
+
+// String returns the chart of strategy s. Two card hands use the
+// notation of H hit, S stand, P split, D double or else hit, Ds double or
+// else stand, Rh, Rs and Rp surrender or else hit, stand or split. Hands
+// of three or more cards hit or stand.
+func (s *Strategy) String() string {
+	var b strings.Builder
+	ups := []int{1, 2, 3, 4, 5, 6, 7, 8, ten, ace}
+	header := func(title string) {
+		fmt.Fprintf(&b, "%-8s", title)
+		for _, up := range ups {
+			fmt.Fprintf(&b, "%4s", valueName(up))
+		}
+		b.WriteByte('\n')
+	}
+	row := func(name string, k key) {
+		fmt.Fprintf(&b, "%-8s", name)
+		for _, up := range ups {
+			code := "-"
+			if e := s.charts[up][k]; e != nil {
+				code = e.code()
+			}
+			fmt.Fprintf(&b, "%4s", code)
+		}
+		b.WriteByte('\n')
+	}
+
+	header("Hard")
+	for t := 5; t <= 19; t++ {
+		row(strconv.Itoa(t), key{hard, t, 2})
+	}
+	header("Soft")
+	for v := 1; v <= 8; v++ {
+		row("A,"+valueName(v), key{softHand, 12 + v, 2})
+	}
+	header("Pair")
+	for _, v := range append(ups[:9:9], ace) {
+		row(valueName(v)+","+valueName(v), key{pair, v, 2})
+	}
+
+	// Hands of three cards, or more if it makes no difference.
+	more := " 3"
+	if s.cards == 3 {
+		more = " 3+"
+	}
+	header("Hard" + more)
+	for t := 12; t <= 20; t++ {
+		row(strconv.Itoa(t), key{hard, t, 3})
+	}
+	header("Soft" + more)
+	for t := 13; t <= 20; t++ {
+		row(strconv.Itoa(t), key{softHand, t, 3})
+	}
+	return b.String()
+}
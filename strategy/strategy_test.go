@@ -0,0 +1,262 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/dwlnetnl/cards/blackjack"
+	"github.com/dwlnetnl/cards/card"
+	"github.com/dwlnetnl/cards/player"
+
+	"github.com/shopspring/decimal"
+)
+
+func mustParse(t *testing.T, s string) *blackjack.RuleSet {
+	rs, err := blackjack.ParseRules(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs
+}
+
+// cardOf returns a card named A, 2 to 9 or T of suit s.
+func cardOf(name string, s card.Suit) card.Card {
+	r := card.Ten
+	switch name {
+	case "A":
+		r = card.Ace
+	case "T":
+	default:
+		r = card.Two + card.Rank(name[0]-'2')
+	}
+	return card.Card{Suit: s, Rank: r}
+}
+
+// hand returns the hand of cards named like "T,6" of mixed suits.
+func hand(names string) blackjack.Hand {
+	var h blackjack.Hand
+	for i, name := range strings.Split(names, ",") {
+		h = append(h, cardOf(name, card.Suit(i%4)))
+	}
+	return h
+}
+
+func upOf(name string) card.Card { return cardOf(name, card.Clubs) }
+
+func TestDealerOdds(t *testing.T) {
+	// Bust odds of a dealer that stands on soft 17, after peeking for
+	// blackjack.
+	want := []float64{0.1665, 0.3536, 0.3739, 0.3945, 0.4164, 0.4232, 0.2623, 0.2447, 0.2284, 0.2298}
+	sv := newSolver(mustParse(t, "6D S17 PEEK 3:2"), Infinite)
+	for up, w := range want {
+		x := sv.upCard(up)
+		bust := 0.0
+		for i, p := range x.holeOdds(comp{}) {
+			bust += p * x.dealerOdds(comp{}, i)[dealerBust]
+		}
+		if math.Abs(bust-w) > 0.0005 {
+			t.Errorf("up-card %s: got bust odds %.4f, want: %.4f", valueName(up), bust, w)
+		}
+	}
+}
+
+// computed has the strategies computed by the tests by rules and decks.
+var computed = make(map[string]*Strategy)
+
+func strategyOf(t *testing.T, rules string, decks uint) *Strategy {
+	k := fmt.Sprint(rules, " ", decks)
+	if s, ok := computed[k]; ok {
+		return s
+	}
+	s := NewDecks(mustParse(t, rules), decks)
+	computed[k] = s
+	return s
+}
+
+func TestChart(t *testing.T) {
+	const (
+		lv   = "6D S17 DAS LS SP4 3:2"
+		lv17 = "6D H17 DAS LS SP4 3:2"
+	)
+	cases := []struct {
+		rules string
+		decks uint
+		hand  string
+		up    string
+		want  string
+	}{
+		{lv, Infinite, "T,6", "T", "Rh"},
+		{lv, Infinite, "T,6", "6", "S"},
+		{lv, Infinite, "T,2", "2", "H"},
+		{lv, Infinite, "T,2", "4", "S"},
+		{lv, Infinite, "6,5", "A", "H"},
+		{lv, Infinite, "6,4", "T", "H"},
+		{lv, Infinite, "6,3", "3", "D"},
+		{lv, Infinite, "A,7", "3", "Ds"},
+		{lv, Infinite, "A,7", "9", "H"},
+		{lv, Infinite, "A,8", "6", "S"},
+		{lv, Infinite, "8,8", "A", "P"},
+		{lv, Infinite, "9,9", "7", "S"},
+		{lv, Infinite, "4,4", "5", "P"},
+		{lv, Infinite, "6,6", "2", "P"},
+		{lv, Infinite, "T,2,4", "T", "H"},
+		{lv17, Infinite, "6,5", "A", "D"},
+		{lv17, Infinite, "A,8", "6", "Ds"},
+		{lv17, Infinite, "T,7", "A", "Rs"},
+		{lv17, Infinite, "8,8", "A", "Rp"},
+		{"6D S17 3:2", Infinite, "4,4", "5", "H"},
+		{"6D S17 3:2", Infinite, "6,6", "2", "H"},
+		{"6D S17 DAS ENHC 3:2", Infinite, "6,5", "T", "H"},
+		{"6D S17 DAS ENHC 3:2", Infinite, "8,8", "T", "H"},
+		{"6D S17 DAS ENHC 3:2", Infinite, "A,A", "A", "H"},
+		{"6D S17 DAS ENHC OBO 3:2", Infinite, "6,5", "T", "D"},
+		{"6D S17 DAS ENHC OBO 3:2", Infinite, "8,8", "T", "P"},
+		{"6D S17 DAS ES 3:2", Infinite, "2,5", "A", "Rh"},
+		{"6D S17 DAS ES 3:2", Infinite, "T,4", "T", "Rh"},
+		{"6D S17 DAS D10 3:2", Infinite, "6,3", "3", "H"},
+		{"6D S17 DAS LS 3:2", 6, "T,2,4", "T", "S"},
+		{"1D S17 DAS LS 3:2", 1, "7,7", "T", "Rs"},
+		{"1D S17 DAS LS 3:2", 1, "3,5", "6", "D"},
+		{"1D S17 DAS LS 3:2", 1, "A,7", "A", "S"},
+	}
+
+	for _, c := range cases {
+		if c.decks != Infinite && testing.Short() {
+			continue
+		}
+		s := strategyOf(t, c.rules, c.decks)
+		e := s.entry(hand(c.hand), upOf(c.up))
+		if e == nil {
+			t.Errorf("%s, %d decks: no entry for %s against %s", c.rules, c.decks, c.hand, c.up)
+			continue
+		}
+		if got := e.code(); got != c.want {
+			t.Errorf("%s, %d decks: got %s for %s against %s, want: %s", c.rules, c.decks, got, c.hand, c.up, c.want)
+		}
+	}
+}
+
+func TestEV(t *testing.T) {
+	ev := strategyOf(t, "6D S17 DAS LS SP4 3:2", Infinite).EV()
+	if ev < -0.006 || ev > -0.003 {
+		t.Errorf("got EV %.4f, want a house edge of 0.3%% to 0.6%%", ev)
+	}
+
+	cases := []struct {
+		rules    string
+		min, max float64 // change of EV
+	}{
+		{"6D H17 DAS LS SP4 3:2", -0.0025, -0.0015},
+		{"6D S17 DAS LS SP4 6:5", -0.015, -0.013},
+		{"6D S17 DAS LS SP4 3:2 DWT", -0.1, -0.08},
+		{"6D S17 DAS SP4 3:2", -0.001, -0.0005},
+		{"6D S17 DAS ES SP4 3:2", 0.004, 0.007},
+		{"6D S17 DAS ENHC SP4 3:2", -0.0025, -0.0015},
+		{"6D S17 DAS LS SP4 3:2 5CC", 0.01, 0.02},
+	}
+	for _, c := range cases {
+		d := strategyOf(t, c.rules, Infinite).EV() - ev
+		if d < c.min || d > c.max {
+			t.Errorf("%s: got EV change %.4f, want: %.4f to %.4f", c.rules, d, c.min, c.max)
+		}
+	}
+}
+
+func TestDecks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("computes a shoe of many decks")
+	}
+
+	// A shoe of many decks is almost an infinite shoe.
+	const rules = "6D H17 DAS LS SP4 3:2"
+	inf, many := strategyOf(t, rules, Infinite), strategyOf(t, rules, 400)
+	if d := math.Abs(many.EV() - inf.EV()); d > 0.0001 {
+		t.Errorf("got EV %.5f, want about: %.5f", many.EV(), inf.EV())
+	}
+	if many.String() != inf.String() {
+		t.Errorf("got chart:\n%v\nwant:\n%v", many, inf)
+	}
+
+	// Removing cards helps in a single deck.
+	one, six := strategyOf(t, "1D S17 DAS LS 3:2", 1), strategyOf(t, "6D S17 DAS LS 3:2", 6)
+	if one.EV() <= six.EV() {
+		t.Errorf("got EV %.4f for a single deck, want more than for 6 decks: %.4f", one.EV(), six.EV())
+	}
+}
+
+func TestAction(t *testing.T) {
+	const (
+		hit   = blackjack.Hit
+		stand = blackjack.Stand
+		dbl   = blackjack.Double
+		sur   = blackjack.Surrender
+		cont  = blackjack.Continue
+	)
+
+	cases := []struct {
+		rules   string
+		hand    string
+		up      string
+		allowed []blackjack.Action
+		want    blackjack.Action
+	}{
+		{"6D S17 DAS LS 3:2", "6,5", "6", []blackjack.Action{hit, stand, dbl}, dbl},
+		{"6D S17 DAS LS 3:2", "6,5", "6", []blackjack.Action{hit, stand}, hit},
+		{"6D S17 DAS LS 3:2", "T,6", "T", []blackjack.Action{hit, stand, sur}, sur},
+		{"6D S17 DAS LS 3:2", "T,6", "T", []blackjack.Action{hit, stand}, hit},
+		{"6D S17 DAS LS 3:2", "T,2,5", "T", []blackjack.Action{hit, stand}, stand},
+		{"6D S17 DAS LS 3:2", "A,6", "T", []blackjack.Action{stand}, stand},
+		{"6D S17 DAS LS 3:2", "A,T", "T", []blackjack.Action{stand}, stand},
+		{"6D S17 DAS ES 3:2", "T,6", "T", []blackjack.Action{sur, cont}, sur},
+		{"6D S17 DAS ES 3:2", "T,9", "T", []blackjack.Action{sur, cont}, cont},
+	}
+
+	for _, c := range cases {
+		s := strategyOf(t, c.rules, Infinite)
+		if got := s.Action(hand(c.hand), upOf(c.up), c.allowed); got != c.want {
+			t.Errorf("%s: got %v for %s against %s with %v, want: %v", c.rules, got, c.hand, c.up, c.allowed, c.want)
+		}
+	}
+
+	s := strategyOf(t, "6D S17 DAS LS 3:2", Infinite)
+	values := s.Values(hand("T,6"), upOf("T"))
+	if len(values) != 4 || values[hit] <= values[stand] {
+		t.Errorf("got values %v, want hit, stand, double and surrender with hit above stand", values)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	const rounds = 300
+	for _, r := range blackjack.AvailableRules {
+		t.Run(blackjack.RulesName(r), func(t *testing.T) {
+			s := NewDecks(r, Infinite)
+			tbl := blackjack.NewSeededTable(r, 1)
+			if err := tbl.Sit(0, player.NewFortune(decimal.New(100000, 0))); err != nil {
+				t.Fatal(err)
+			}
+
+			bet := decimal.New(10, 0)
+			if min := r.Limits().Min; min.GreaterThan(bet) {
+				bet = min
+			}
+			n := 0
+			for tbl.State().Phase != blackjack.PhaseClosed {
+				var m blackjack.Move
+				switch st := tbl.State(); st.Phase {
+				case blackjack.PhaseBet:
+					m.Amount = bet
+				case blackjack.PhaseNewGame:
+					n++
+					m.Accept = n < rounds
+				case blackjack.PhaseAction:
+					m.Action = s.Action(st.Hand, st.Dealer.UpCard(), tbl.LegalActions())
+				}
+				if _, err := tbl.Apply(m); err != nil {
+					t.Fatalf("round %d: %v", n, err)
+				}
+			}
+		})
+	}
+}